@@ -10,12 +10,21 @@ import (
 )
 
 func SetupDBConnection() *gorm.DB {
-	db, err := gorm.Open(postgres.Open(os.Getenv("DB_CONN")), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(os.Getenv("DB_CONN")), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Failed to connect to database!")
 	}
 
-	db.AutoMigrate(&models.User{}, &models.Restaurant{}, &models.Reservation{}, &models.Comment{})
+	// Accounts from before email verification existed count as verified, otherwise they couldn't book anymore
+	backfillVerified := !db.Migrator().HasColumn(&models.User{}, "email_verified_at")
+
+	db.AutoMigrate(&models.User{}, &models.Restaurant{}, &models.Reservation{}, &models.Comment{}, &models.Session{}, &models.UserToken{}, &models.RecoveryCode{}, &models.LoginAttempt{}, &models.Table{}, &models.ReservationStatusChange{}, &models.OpeningInterval{}, &models.OpeningException{}, &models.WaitlistEntry{}, &models.BookingPolicy{}, &models.WalkIn{}, &models.ReservationHistory{}, &models.ReservationSeries{})
+
+	if backfillVerified {
+		if err := db.Model(&models.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			log.Fatal("Failed to mark existing users as verified!")
+		}
+	}
 
 	return db
 }
\ No newline at end of file
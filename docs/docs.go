@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/register": {
+        "/auth/forgot-password": {
             "post": {
-                "description": "Creates a new user account with the provided details. Upon successful creation, the user can log in with their credentials.",
+                "description": "Sends a password reset link to the given email. The response is the same whether or not the email belongs to an account.",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,23 +27,23 @@ const docTemplate = `{
                 "tags": [
                     "authentication"
                 ],
-                "summary": "Register a new user",
+                "summary": "Forgot Password",
                 "parameters": [
                     {
-                        "description": "Register Credentials",
-                        "name": "user",
+                        "description": "Account Email",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.RegisterDetails"
+                            "$ref": "#/definitions/api.ForgotPasswordDetails"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Confirmation of successful registration.",
+                        "description": "The reset link has been sent if the account exists.",
                         "schema": {
-                            "$ref": "#/definitions/api.RegisterResponse"
+                            "$ref": "#/definitions/api.MessageResponse"
                         }
                     },
                     "400": {
@@ -51,61 +51,34 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal server error, unable to process the request.",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
                     }
                 }
             }
         },
-        "/auth/signin": {
+        "/auth/logout": {
             "post": {
-                "description": "Authenticates a user by their email and password, returning a JWT token for authorized access to protected endpoints if successful.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Revokes the current session. The access token and refresh token of this session stop working immediately.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "authentication"
                 ],
-                "summary": "User Login",
-                "parameters": [
-                    {
-                        "description": "Login Credentials",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.LoginDetails"
-                        }
-                    }
-                ],
+                "summary": "Logout",
                 "responses": {
                     "200": {
-                        "description": "An object containing a JWT token for authentication and a message indicating successful login.",
-                        "schema": {
-                            "$ref": "#/definitions/api.LoginResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "The request was formatted incorrectly or missing required fields.",
+                        "description": "Confirmation of successful logout.",
                         "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                            "$ref": "#/definitions/api.MessageResponse"
                         }
                     },
                     "401": {
-                        "description": "Authentication failed due to invalid login credentials.",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "The specified user was not found in the system.",
+                        "description": "Unauthorized.",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -119,46 +92,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/comments": {
-            "get": {
+        "/auth/logout-all": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a list of all comments in the system.",
+                "description": "Revokes every session of the current user, including the one making the request.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "authentication"
                 ],
-                "summary": "Get All Comments",
+                "summary": "Logout From All Devices",
                 "responses": {
                     "200": {
-                        "description": "An array of comment objects.",
+                        "description": "Confirmation of successful logout.",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Comment"
-                            }
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while fetching comments.",
+                        "description": "Internal server error, unable to process the request.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/mfa/confirm": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Adds a new comment to the system with customer's opinion. This endpoint requires authentication.",
+                "description": "Turns two-factor authentication on after checking a code from the authenticator app. Returns one-time recovery codes, which are only shown once, and a new access token for the current session.",
                 "consumes": [
                     "application/json"
                 ],
@@ -166,259 +144,356 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "authentication"
                 ],
-                "summary": "Create a New Comment",
+                "summary": "Confirm MFA Enrollment",
                 "parameters": [
                     {
-                        "description": "Your Comment",
-                        "name": "commnet",
+                        "description": "Code from the Authenticator App",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Comment"
+                            "$ref": "#/definitions/api.MFACodeDetails"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "The created comment's details, including its unique identifier.",
+                    "200": {
+                        "description": "Recovery codes and a new access token.",
                         "schema": {
-                            "$ref": "#/definitions/models.Comment"
+                            "$ref": "#/definitions/api.MFAConfirmResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format for reservation details.",
+                        "description": "Enrollment was not started, is already done, or the code is invalid.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while creating the reservation.",
+                        "description": "Internal server error, unable to process the request.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/comments/{id}": {
-            "get": {
+        "/auth/mfa/disable": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves details of a single commnet by its unique identifier.",
+                "description": "Turns two-factor authentication off for the current user. Requires a TOTP code or a recovery code. Not allowed for roles where two-factor authentication is mandatory.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "authentication"
                 ],
-                "summary": "Get a Single Comment",
+                "summary": "Disable MFA",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Comment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Code from the Authenticator App or a Recovery Code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.MFACodeDetails"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "The details of the comment including ID, DateTime, Detail, UserID, User, RestaurantID, and Restaurant.",
+                        "description": "Two-factor authentication has been disabled.",
                         "schema": {
-                            "$ref": "#/definitions/models.Comment"
+                            "$ref": "#/definitions/api.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid comment ID format.",
+                        "description": "Two-factor authentication is not enabled or the code is invalid.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Comment not found with the specified ID.",
+                    "401": {
+                        "description": "Unauthorized.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Two-factor authentication is mandatory for this role.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/auth/mfa/enroll": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates the details of an existing comment identified by its ID. This endpoint requires authentication.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Generates a new TOTP secret for the current user. Add it to an authenticator app and call /auth/mfa/confirm with a code to turn two-factor authentication on.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
-                ],
-                "summary": "Update a Comment",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Comment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated comment Details",
-                        "name": "comment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Comment"
-                        }
-                    }
+                    "authentication"
                 ],
+                "summary": "Start MFA Enrollment",
                 "responses": {
                     "200": {
-                        "description": "The updated comment's details.",
+                        "description": "The TOTP secret and an otpauth:// url for QR codes.",
                         "schema": {
-                            "$ref": "#/definitions/models.Comment"
+                            "$ref": "#/definitions/api.MFAEnrollResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format for comment details or invalid comment ID.",
+                        "description": "Two-factor authentication is already enabled.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Comment not found with the specified ID.",
+                    "401": {
+                        "description": "Unauthorized.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+            }
+        },
+        "/auth/mfa/verify": {
+            "post": {
+                "description": "Exchanges the MFA challenge token from /auth/signin together with a TOTP code (or a recovery code) for an access token and refresh token.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Removes a comment from the system. This endpoint requires authentication.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "authentication"
                 ],
-                "summary": "Delete a Comment",
+                "summary": "Verify MFA Challenge",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Comment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "MFA Challenge and Code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.MFAVerifyDetails"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Comment successfully deleted, no content to return."
+                    "200": {
+                        "description": "An access token and refresh token.",
+                        "schema": {
+                            "$ref": "#/definitions/api.TokenResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid comment ID format.",
+                        "description": "The request was formatted incorrectly or missing required fields.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Comment not found with the specified ID.",
+                    "401": {
+                        "description": "The challenge token or the code is invalid.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/me": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new access token. The refresh token is rotated, so the one sent in the request can't be used again.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieves the details of the currently authenticated user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "authentication"
+                ],
+                "summary": "Refresh Access Token",
+                "parameters": [
+                    {
+                        "description": "Refresh Token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RefreshDetails"
+                        }
+                    }
                 ],
-                "summary": "Get my profile",
                 "responses": {
                     "200": {
-                        "description": "The details of the currently authenticated user.",
+                        "description": "A new access token and refresh token.",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/api.TokenResponse"
                         }
                     },
-                    "404": {
-                        "description": "User not found.",
+                    "400": {
+                        "description": "The request was formatted incorrectly or missing required fields.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "The refresh token is invalid, expired or revoked.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/reservations": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/register": {
+            "post": {
+                "description": "Creates a new user account with the provided details and sends a verification link to the email. The user can log in right away, but can't make reservations or comments until the email is verified.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieves a list of all reservations in the system.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "authentication"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "Register Credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RegisterDetails"
+                        }
+                    }
                 ],
-                "summary": "Get All Reservations",
                 "responses": {
                     "200": {
-                        "description": "An array of reservation objects.",
+                        "description": "Confirmation of successful registration.",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Reservation"
-                            }
+                            "$ref": "#/definitions/api.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "The request was formatted incorrectly or missing required fields.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while fetching reservations.",
+                        "description": "Internal server error, unable to process the request.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/resend-verification": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Adds a new reservation to the system with the provided details. This endpoint requires authentication.",
+                "description": "Sends a new verification link to the email of the current user. Older links stop working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Resend Verification Email",
+                "responses": {
+                    "200": {
+                        "description": "The verification link has been sent.",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "The email is already verified.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Sets a new password using the token from the reset link. The token can only be used once, and every session of the account is logged out.",
                 "consumes": [
                     "application/json"
                 ],
@@ -426,95 +501,103 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "authentication"
                 ],
-                "summary": "Create a New Reservation",
+                "summary": "Reset Password",
                 "parameters": [
                     {
-                        "description": "Reservation Details",
-                        "name": "reservation",
+                        "description": "Reset Token and New Password",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Reservation"
+                            "$ref": "#/definitions/api.ResetPasswordDetails"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "The created reservation's details, including its unique identifier.",
+                    "200": {
+                        "description": "The password has been changed.",
                         "schema": {
-                            "$ref": "#/definitions/models.Reservation"
+                            "$ref": "#/definitions/api.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format for reservation details.",
+                        "description": "The token is invalid, expired or already used, or the password is too short.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while creating the reservation.",
+                        "description": "Internal server error, unable to process the request.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/reservations/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/signin": {
+            "post": {
+                "description": "Authenticates a user by their email and password, returning a short-lived JWT token for authorized access to protected endpoints and a refresh token to renew it.\nIf the account has two-factor authentication enabled, an MFA challenge token is returned instead, which must be exchanged at /auth/mfa/verify.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieves details of a single reservation by its unique identifier.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "authentication"
                 ],
-                "summary": "Get a Single Reservation",
+                "summary": "User Login",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Reservation ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Login Credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.LoginDetails"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "The details of the reservation including ID, DateTime, UserID, User, RestaurantID, and Restaurant.",
+                        "description": "An object containing a JWT token for authentication and a message indicating successful login.",
                         "schema": {
-                            "$ref": "#/definitions/models.Reservation"
+                            "$ref": "#/definitions/api.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid reservation ID format.",
+                        "description": "The request was formatted incorrectly or missing required fields.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Reservation not found with the specified ID.",
+                    "401": {
+                        "description": "Authentication failed due to invalid login credentials.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts from this ip or for this account, try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Updates the details of an existing reservation identified by its ID. This endpoint requires authentication.",
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "Confirms the email address of an account using the token from the verification link.",
                 "consumes": [
                     "application/json"
                 ],
@@ -522,85 +605,87 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "authentication"
                 ],
-                "summary": "Update a Reservation",
+                "summary": "Verify Email",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Reservation ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated Reservation Details",
-                        "name": "reservation",
+                        "description": "Verification Token",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Reservation"
+                            "$ref": "#/definitions/api.VerifyEmailDetails"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "The updated reservation's details.",
+                        "description": "The email has been verified.",
                         "schema": {
-                            "$ref": "#/definitions/models.Reservation"
+                            "$ref": "#/definitions/api.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format for reservation details or invalid reservation ID.",
+                        "description": "The token is invalid, expired or already used.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Reservation not found with the specified ID.",
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/availability": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Removes a reservation from the system by its unique identifier. This endpoint requires authentication.",
+                "description": "Finds the restaurants that are open and still have a table for the party for 2 hours from the given time. Restaurants whose booking policy doesn't allow the booking, too soon, too far ahead or for too big a party, are left out.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "restaurants"
                 ],
-                "summary": "Delete a Reservation",
+                "summary": "Search Availability",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Reservation ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Start of the booking, RFC 3339 like 2024-05-01T18:00:00+07:00",
+                        "name": "dateTime",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of guests, defaults to 1",
+                        "name": "partySize",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Reservation successfully deleted, no content to return."
+                    "200": {
+                        "description": "The restaurants with a free table and how many tables are left.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.AvailabilitySearchResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid reservation ID format.",
+                        "description": "Invalid dateTime or party size.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Reservation not found with the specified ID.",
+                    "500": {
+                        "description": "Internal server error while searching.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -608,46 +693,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants": {
+        "/booking-policy": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a list of all restaurants in the system.",
+                "description": "Returns the default booking rules used by restaurants without their own. A limit of 0 means no limit.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "booking policy"
                 ],
-                "summary": "Get All Restaurants",
+                "summary": "Get the Global Booking Policy",
                 "responses": {
                     "200": {
-                        "description": "An array of restaurant objects.",
+                        "description": "The stored global policy and the rules that apply.",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Restaurant"
-                            }
+                            "$ref": "#/definitions/v1.BookingPolicyResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while fetching restaurants.",
+                        "description": "Internal server error while fetching the policy.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Adds a new restaurant to the system with the provided details.",
+                "description": "Replaces the default booking rules: how many upcoming active bookings a user may hold across all restaurants, how many minutes ahead a booking must be made, how many days ahead it may be made, how many guests a party may have, until how many hours before the booking guests may cancel, and after how many no-shows within how many days a guest can't book anymore. Fields left out fall back to the built-in defaults, 0 means no limit. Only admins can change it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -655,35 +737,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "booking policy"
                 ],
-                "summary": "Create a New Restaurant",
+                "summary": "Update the Global Booking Policy",
                 "parameters": [
                     {
-                        "description": "Restaurant Registration Details",
-                        "name": "restaurant",
+                        "description": "Booking Rules",
+                        "name": "policy",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
+                            "$ref": "#/definitions/v1.BookingPolicyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "The created restaurant's details, including its unique identifier.",
+                    "200": {
+                        "description": "The saved policy and the rules that apply.",
                         "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
+                            "$ref": "#/definitions/v1.BookingPolicyResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format for restaurant details.",
+                        "description": "Invalid input format, the fields that are wrong are listed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins can change the global policy.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while creating the restaurant.",
+                        "description": "Internal server error while saving the policy.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -691,194 +779,228 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{id}": {
+        "/comments": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves details of a single restaurant by its unique identifier.",
+                "description": "Retrieves a list of all comments in the system.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
-                ],
-                "summary": "Get a Single Restaurant",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Restaurant ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "comments"
                 ],
+                "summary": "Get All Comments",
                 "responses": {
                     "200": {
-                        "description": "The details of the restaurant including ID, name, location, and other relevant information.",
+                        "description": "An array of comment objects.",
                         "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Comment"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching comments.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a new comment to the system with customer's opinion. This endpoint requires authentication.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Create a New Comment",
+                "parameters": [
+                    {
+                        "description": "Your Comment",
+                        "name": "commnet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created comment's details, including its unique identifier.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
                         }
                     },
                     "400": {
-                        "description": "Invalid restaurant ID format.",
+                        "description": "Invalid input format for reservation details.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Restaurant not found with the specified ID.",
+                    "500": {
+                        "description": "Internal server error while creating the reservation.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/comments/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates the details of an existing restaurant identified by its ID.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retrieves details of a single commnet by its unique identifier.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "comments"
                 ],
-                "summary": "Update a Restaurant",
+                "summary": "Get a Single Comment",
                 "parameters": [
                     {
                         "type": "integer",
                         "format": "int64",
-                        "description": "Restaurant ID",
+                        "description": "Comment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated Restaurant Details",
-                        "name": "restaurant",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "The updated restaurant's details.",
+                        "description": "The details of the comment including ID, DateTime, Detail, UserID, User, RestaurantID, and Restaurant.",
                         "schema": {
-                            "$ref": "#/definitions/models.Restaurant"
+                            "$ref": "#/definitions/models.Comment"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format for restaurant details or invalid restaurant ID.",
+                        "description": "Invalid comment ID format.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Restaurant not found with the specified ID.",
+                        "description": "Comment not found with the specified ID.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Removes a restaurant from the system by its unique identifier.",
+                "description": "Updates the details of an existing comment identified by its ID. This endpoint requires authentication.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "restaurants"
+                    "comments"
                 ],
-                "summary": "Delete a Restaurant",
+                "summary": "Update a Comment",
                 "parameters": [
                     {
                         "type": "integer",
                         "format": "int64",
-                        "description": "Restaurant ID",
+                        "description": "Comment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Updated comment Details",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Restaurant successfully deleted, no content to return."
+                    "200": {
+                        "description": "The updated comment's details.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
                     },
                     "400": {
-                        "description": "Invalid restaurant ID format.",
+                        "description": "Invalid input format for comment details or invalid comment ID.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Restaurant not found with the specified ID.",
+                        "description": "Comment not found with the specified ID.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/restaurants/{restaurantID}/comments": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a list of comments associated with a specific restaurant.",
+                "description": "Removes a comment from the system. This endpoint requires authentication.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "comments"
                 ],
-                "summary": "Get Reataurant's Comments",
+                "summary": "Delete a Comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Reataurant ID",
-                        "name": "restaurantId",
+                        "format": "int64",
+                        "description": "Comment ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "An array of comment objects for the restaurant.",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Comment"
-                            }
-                        }
+                    "204": {
+                        "description": "Comment successfully deleted, no content to return."
                     },
                     "400": {
-                        "description": "Invalid reataurant ID format.",
+                        "description": "Invalid comment ID format.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Comments not found for the specified restaurant ID.",
+                        "description": "Comment not found with the specified ID.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -886,46 +1008,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/lockouts": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a list of all users in the system.",
+                "description": "Lists the accounts and ips that are currently locked out after too many failed logins, together with the failed attempts of the last 24 hours.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "authentication"
                 ],
-                "summary": "Get All Users",
+                "summary": "Get Login Lockouts",
                 "responses": {
                     "200": {
-                        "description": "An array of user objects.",
+                        "description": "Current lockouts and recent failed attempts.",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
+                            "$ref": "#/definitions/api.LockoutsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins can see lockouts.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while fetching users.",
+                        "description": "Internal server error, unable to process the request.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Adds a new user to the system with the provided details.",
+                "description": "Lets an account or an ip log in again right away and forgets its failed attempts. The failures of the account from every ip and of the ip for every account are forgotten.",
                 "consumes": [
                     "application/json"
                 ],
@@ -933,95 +1058,118 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "authentication"
                 ],
-                "summary": "Create a New User",
+                "summary": "Clear a Login Lockout",
                 "parameters": [
                     {
-                        "description": "User Registration Details",
-                        "name": "user",
+                        "description": "Account Email and/or IP",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/api.ClearLockoutDetails"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "The created user's details, including their unique identifier.",
+                    "200": {
+                        "description": "The lockout has been cleared.",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/api.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format for user details.",
+                        "description": "Neither an email nor an ip was given.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins can clear lockouts.",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while creating the user.",
+                        "description": "Internal server error, unable to process the request.",
                         "schema": {
-                            "$ref": "#/definitions/v1.ErrorResponse"
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/users/{id}": {
+        "/me": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves details of a single user by their unique identifier.",
+                "description": "Retrieves the details of the currently authenticated user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "user"
                 ],
-                "summary": "Get a Single User",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Get my profile",
                 "responses": {
                     "200": {
-                        "description": "The details of the user including ID, name, email, telephone, and role.",
+                        "description": "The details of the currently authenticated user.",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.OwnerUser"
                         }
                     },
-                    "400": {
-                        "description": "Invalid user ID format.",
+                    "404": {
+                        "description": "User not found.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/me/calendar-feed": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a secret url with all of the current user's reservations in iCalendar format, to subscribe to from a calendar app. Calling it again replaces the token and the old url stops working. The token is only shown once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Create my Calendar Feed",
+                "responses": {
+                    "200": {
+                        "description": "The feed url and its token.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.CalendarFeedResponse"
+                        }
                     },
-                    "404": {
-                        "description": "User not found with the specified ID.",
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/me/password": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates the details of an existing user identified by their ID.",
+                "description": "Changes the password of the currently authenticated user. The current password is required, and every other session is logged out.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1031,83 +1179,183 @@ const docTemplate = `{
                 "tags": [
                     "user"
                 ],
-                "summary": "Update a User",
+                "summary": "Change my password",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated User Details",
-                        "name": "user",
+                        "description": "Current and New Password",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/v1.ChangePasswordRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "The updated user's details.",
+                        "description": "The password has been changed.",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/v1.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format for user details or invalid user ID.",
+                        "description": "Invalid input or the new password is too short.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "The current password is incorrect.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while updating the user.",
+                        "description": "Internal server error while changing the password.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
+            }
+        },
+        "/queue": {
+            "get": {
+                "security": [
+                    {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Removes a user from the system by their unique identifier.",
+                "description": "Lists the current user's tickets that are still in a queue, with their position and estimated wait.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "queue"
                 ],
-                "summary": "Delete a User",
+                "summary": "Get My Queue Tickets",
+                "responses": {
+                    "200": {
+                        "description": "The user's queue tickets.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WalkIn"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching the queue.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the ticket with its current position and estimated wait, for guests to poll while they wait. The guest, the restaurant's staff and admins can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Get a Queue Ticket",
                 "parameters": [
                     {
                         "type": "integer",
                         "format": "int64",
-                        "description": "User ID",
+                        "description": "Queue Ticket ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "User successfully deleted, no content to return."
+                    "200": {
+                        "description": "The queue ticket.",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalkIn"
+                        }
                     },
                     "400": {
-                        "description": "Invalid user ID format.",
+                        "description": "Invalid queue ID format.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error while deleting the user.",
+                    "403": {
+                        "description": "Not allowed to see this ticket.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Queue ticket not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Takes the ticket out of the queue. The guest, the restaurant's staff and admins can do this.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Leave a Walk-in Queue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Queue Ticket ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The queue ticket, now left.",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalkIn"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid queue ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change this ticket.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Queue ticket not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The ticket is no longer in the queue.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
@@ -1115,93 +1363,4758 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userId}/reservations": {
-            "get": {
+        "/queue/{id}/call": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a list of reservations associated with a specific user.",
+                "description": "Lets the guest know their table is nearly ready. Only the restaurant's staff and admins can call guests.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reservations"
+                    "queue"
                 ],
-                "summary": "Get User's Reservations",
+                "summary": "Call a Walk-in",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userId",
+                        "format": "int64",
+                        "description": "Queue Ticket ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "An array of reservation objects for the user.",
+                        "description": "The queue ticket, now called.",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Reservation"
-                            }
+                            "$ref": "#/definitions/models.WalkIn"
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID format.",
+                        "description": "Invalid queue ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change this ticket.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Reservations not found for the specified user ID.",
+                        "description": "Queue ticket not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The ticket can't be called from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}/finish": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks a seated walk-in as gone, so their table can be booked again. Only the restaurant's staff and admins can do this.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Finish a Walk-in",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Queue Ticket ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The queue ticket, now finished.",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalkIn"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid queue ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change this ticket.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Queue ticket not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The walk-in isn't seated.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}/seat": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Seats the guest on the given table, or on the smallest free table that fits the party when tableNum is left out. Walk-ins only get a table that has no booking for the next 2 hours. The table is kept for them for those 2 hours, or until they are marked finished if that's sooner, after that it can be booked again. Only the restaurant's staff and admins can seat guests.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Seat a Walk-in",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Queue Ticket ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Table to Seat the Guest at",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SeatWalkInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The queue ticket, now seated.",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalkIn"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid queue ID, or the table doesn't exist or is too small.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change this ticket.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Queue ticket not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The ticket can't be seated from its current status, or no table is free, the booking in the way is returned.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ConflictResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue/{id}/skip": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Takes a guest who didn't answer the call out of the queue. Only the restaurant's staff and admins can skip guests.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Skip a Walk-in",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Queue Ticket ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The queue ticket, now skipped.",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalkIn"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid queue ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change this ticket.",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Queue ticket not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The ticket can't be skipped from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservation-series": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Books the same table every week or every month. frequency is weekly or monthly and interval is how many weeks or months apart the bookings are (1 when left out). The series ends after count occurrences or at until, exactly one of them is required, and can have at most 52 occurrences. dateTime and exitTime are the first occurrence, the others keep its time of day in the restaurant's time zone. Monthly series skip months that don't have the day of the first occurrence. Every occurrence is checked like a single booking, the ones that clash are left out and listed in conflicts. For the booking policy's limit of active bookings the whole series counts as one booking.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Create a Recurring Reservation",
+                "parameters": [
+                    {
+                        "description": "Series Details",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReservationSeries"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The series with the booked occurrences, and the occurrences that couldn't be booked.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SeriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid series details, the fields that are wrong are listed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The user already has as many active bookings as the booking policy allows, or is restricted after too many no-shows.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "None of the occurrences could be booked, what went wrong with each is listed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SeriesConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while creating the series.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservation-series/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a series with all of its occurrences, oldest first. Only the guest who booked, the restaurant's staff and admins can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get a Recurring Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The series and its occurrences.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReservationSeries"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid series ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this series.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of all reservations in the system for admins. Everyone else only gets their own reservations.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get All Reservations",
+                "responses": {
+                    "200": {
+                        "description": "An array of reservation objects.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Reservation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a new reservation to the system with the provided details. This endpoint requires authentication. When tableNum is left out, the smallest free table that seats partySize (1 when left out) for the whole slot is picked. dietaryFlags can be vegetarian, vegan, halal, kosher, gluten_free, dairy_free, nut_allergy and shellfish_allergy, anything else goes into specialRequests (at most 500 characters).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Create a New Reservation",
+                "parameters": [
+                    {
+                        "description": "Reservation Details",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created reservation's details, including its unique identifier.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation details, the fields that are wrong are listed. Reservations must be in the future, end after they start, fit in the opening hours and follow the restaurant's booking policy.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The user already has as many active reservations as the booking policy allows, or is restricted after too many no-shows.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The slot overlaps another booking, the conflicting slot is returned.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while creating the reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves details of a single reservation by its unique identifier. Only the guest who booked, the restaurant's staff and admins can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get a Single Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The details of the reservation including ID, DateTime, UserID, User, RestaurantID, and Restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the details of an existing reservation identified by its ID. Only the guest who booked, the restaurant's staff and admins can update it. For a recurring reservation, scope=following also updates the later occurrences and scope=all every occurrence that hasn't happened yet. A new dateTime moves them by the same number of days and to the same time of day, occurrences that can't be moved are listed in conflicts and keep their old slot. The series itself moves along, so it keeps describing its coming occurrences. If one occurrence can't be changed because of an unexpected error, none of them are. An occurrence moved to another restaurant leaves its series.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Update a Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "this (default), following or all",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Updated Reservation Details",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The updated reservation's details. With scope following or all a models.SeriesChangeResult.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation details, reservation ID or scope, the fields that are wrong are listed, or the reservation is not part of a series.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to update this reservation, or the guest's cancellation deadline has passed and the booking can't be moved anymore.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The new slot overlaps another booking, the conflicting slot is returned, or the reservation is no longer active.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ConflictResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only admins remove the reservation from the system. For the guest who booked and the restaurant's staff it is cancelled like with /reservations/{id}/cancel and the cancelled reservation is returned, so it keeps its status changes and history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Delete a Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The cancelled reservation, when not deleted by an admin.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to delete this reservation, or the guest's cancellation deadline has passed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be cancelled from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}.ics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Downloads a single reservation as an RFC 5545 .ics file to add to Google, Apple or Outlook calendar. Only the guest who booked, the restaurant's staff and admins can get it.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Get a Reservation as iCalendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation as a VCALENDAR.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels a pending or confirmed reservation and frees its table. The guest who booked, the restaurant's staff and admins can cancel. Guests can only cancel until the cancellation deadline of the restaurant's booking policy. For a recurring reservation, scope=following also cancels the later occurrences and scope=all every occurrence that hasn't happened yet, occurrences that can't be cancelled are listed in conflicts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Cancel a Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "this (default), following or all",
+                        "name": "scope",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation with its new status. With scope following or all a models.SeriesChangeResult.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format or scope, or the reservation is not part of a series.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change the status of this reservation, or the cancellation deadline has passed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be cancelled from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks a seated reservation as completed when the guest leaves, which frees the table. Only the restaurant's staff and admins can complete reservations.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Complete a Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation with its new status.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change the status of this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be completed from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves a pending reservation to confirmed. Only the restaurant's staff and admins can confirm.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Confirm a Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation with its new status.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change the status of this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be confirmed from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every change made to a reservation, from its creation to its deletion, with who made it, when, and the fields that changed from what to what. Deleted reservations keep their history. Only the guest who booked, the restaurant's staff and admins can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get Reservation History",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The history entries, oldest first.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReservationHistory"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching the history.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/no-show": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks a confirmed reservation whose guest never arrived as a no-show, which frees the table and adds to the guest's no-show count. Guests with too many recent no-shows can't book, see the booking policy. Only the restaurant's staff and admins can do this.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Mark a Reservation as No-Show",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation with its new status.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change the status of this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be marked as a no-show from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/qr": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a PNG QR code for the guest to show at the front desk. The code holds an encrypted token for this booking and stops working when the booking is moved to another time. Only confirmed reservations get a code. Only the guest who booked, the restaurant's staff and admins can get it.",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get the Check-in QR Code of a Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width and height in pixels, 128 to 1024, defaults to 256",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The QR code.",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID or size.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation is not confirmed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while making the QR code.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/seat": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks a confirmed reservation as seated when the guest arrives. Only the restaurant's staff and admins can seat guests.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Seat a Reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation with its new status.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change the status of this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be seated from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/status-changes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every status change of a reservation with who made it and when. Only the guest who booked, the restaurant's staff and admins can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get Reservation Status Changes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The status changes, oldest first.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReservationStatusChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching the status changes.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of all restaurants in the system.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get All Restaurants",
+                "responses": {
+                    "200": {
+                        "description": "An array of restaurant objects.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Restaurant"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching restaurants.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a new restaurant to the system with the provided details. openTime and closeTime are times of day like 18:00, a closeTime before the openTime means the restaurant closes after midnight.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Create a New Restaurant",
+                "parameters": [
+                    {
+                        "description": "Restaurant Registration Details",
+                        "name": "restaurant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created restaurant's details, including its unique identifier.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format for restaurant details, invalid opening hours are listed per field.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while creating the restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves details of a single restaurant by its unique identifier, with its weekly schedule, upcoming opening exceptions and whether it is open right now in its own time zone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get a Single Restaurant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The details of the restaurant including ID, name, location, and other relevant information.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the details of an existing restaurant identified by its ID. Restaurant owners can update their own restaurant, only admins can change the rating and comment count.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Update a Restaurant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Restaurant Details",
+                        "name": "restaurant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The updated restaurant's details.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format for restaurant details or invalid restaurant ID, invalid opening hours are listed per field.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a restaurant from the system by its unique identifier.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Delete a Restaurant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Restaurant successfully deleted, no content to return."
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/availability": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the slots on a day that can still be booked for the party, computed from the opening hours, the tables and the existing reservations. The day is taken in the restaurant's time zone. Slots start every 30 minutes and last 2 hours. Slots the restaurant's booking policy doesn't allow, too soon, too far ahead or for too big a party, are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Get Restaurant Availability",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day to look at, as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of guests, defaults to 1",
+                        "name": "partySize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The bookable slots with the number of tables left in each.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.AvailabilityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID, date or party size.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while computing availability.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/booking-policy": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the restaurant's own booking rules and the rules that apply after falling back to the global policy.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "booking policy"
+                ],
+                "summary": "Get a Restaurant's Booking Policy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The restaurant's overrides and the rules that apply.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.BookingPolicyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching the policy.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the restaurant's own booking rules. Fields left out fall back to the global policy, 0 means no limit. maxActiveBookings only limits the bookings a user holds at this restaurant, the global limit across all restaurants still applies. Only the restaurant's owner and admins can change it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "booking policy"
+                ],
+                "summary": "Update a Restaurant's Booking Policy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Booking Rules",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.BookingPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The saved overrides and the rules that apply.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.BookingPolicyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format, the fields that are wrong are listed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to edit this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while saving the policy.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/calendar-feed": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a secret url with every booking at the restaurant in iCalendar format, for the owner to share with the staff's calendars. Calling it again replaces the token and the old url stops working. The token is only shown once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Create a Restaurant's Calendar Feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The feed url and its token.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.CalendarFeedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins and the restaurant's owner can create the feed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/check-in": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reads the token from a guest's QR code and marks the reservation as seated. The code has to be for this restaurant, for the booking's current time and for today in the restaurant's time zone. Only available to admins and the restaurant's owner and staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Check in a Guest",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Token from the QR Code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.CheckInDetails"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation, now seated.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "The code is not valid or is for another restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage this restaurant's reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The reservation of the code no longer exists.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The code is outdated, not for today, or the reservation can't be seated from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/exceptions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the opening hours of a single date, like a public holiday. Either closes the whole day or replaces the weekly schedule of that day with special hours. Several exceptions on one date add up to several intervals. Only the restaurant's owner and admins can add exceptions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Add an Opening Exception",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Exception Details",
+                        "name": "exception",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.ExceptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created exception.",
+                        "schema": {
+                            "$ref": "#/definitions/models.OpeningException"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format, the fields that are wrong are listed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to edit this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while saving the exception.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/exceptions/{exceptionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a dated exception so the weekly schedule applies again. Only the restaurant's owner and admins can remove exceptions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Delete an Opening Exception",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Exception ID",
+                        "name": "exceptionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Exception successfully deleted, no content to return."
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID or exception ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to edit this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Exception not found at this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while deleting the exception.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/queue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists today's queue of the restaurant in ticket order with the estimated waits, followed by the walk-ins who are seated right now. Only available to admins and the restaurant's owner and staff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Get a Restaurant's Walk-in Queue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The restaurant's queue.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WalkIn"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this restaurant's queue.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching the queue.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Takes a ticket in today's queue for the next free table, from the guest's phone or at the door. The restaurant's staff can add a guest without an account by giving their name. The ticket comes with the position in the queue and an estimated wait, worked out from how long tables usually take to turn over and the bookings already on them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queue"
+                ],
+                "summary": "Join a Restaurant's Walk-in Queue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Party Size, and a Name when staff add a guest",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.JoinQueueRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The queue ticket.",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalkIn"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, the restaurant is closed, or it has no table for the party.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The email address has not been verified yet.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already in the queue of this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while joining the queue.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/reservations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of reservations made at a specific restaurant, with the party size, dietary flags and special requests of every booking. Can be narrowed down to a date range in the restaurant's time zone, some statuses and a table. Only available to admins and the restaurant's owner and staff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get Restaurant's Reservations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, as YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated statuses, like pending,confirmed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Table number",
+                        "name": "tableNum",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "An array of reservation objects for the restaurant.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Reservation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID or filter.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this restaurant's reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/reservations.ics": {
+            "get": {
+                "description": "Returns every booking at the restaurant from the last 90 days on as an iCalendar feed, with the guest, party size, dietary flags and special requests. Authenticated by the secret token from /restaurants/{id}/calendar-feed instead of a bearer token.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Get a Restaurant's Calendar Feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Feed Token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The bookings as a VCALENDAR.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No feed with this id and token.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/reservations/day": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The day view of the dashboard. Counts the covers (guests) for every hour the restaurant is open and how long every table is booked, next to all the bookings of the day. Cancelled bookings and no-shows are listed but not counted. Only available to admins and the restaurant's owner and staff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get a Restaurant's Day",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day to look at, as YYYY-MM-DD, defaults to today in the restaurant's time zone",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Covers per hour, table occupancy and the bookings of the day.",
+                        "schema": {
+                            "$ref": "#/definitions/models.DayView"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID or date.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this restaurant's reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/reservations/{reservationId}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels a pending or confirmed booking at the restaurant, the freed table is offered to the waitlist. Staff aren't bound by the cancellation deadline. Only available to admins and the restaurant's owner and staff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Cancel a Booking from the Dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "reservationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation with its new status.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant or reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage this restaurant's reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found at this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be cancelled from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/reservations/{reservationId}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves a pending booking at the restaurant to confirmed. Only available to admins and the restaurant's owner and staff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Confirm a Booking from the Dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "reservationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation with its new status.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant or reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage this restaurant's reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found at this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be confirmed from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/reservations/{reservationId}/seat": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks a confirmed booking at the restaurant as seated when the guest arrives. Only available to admins and the restaurant's owner and staff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Seat a Booking from the Dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "reservationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservation with its new status.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant or reservation ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage this restaurant's reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found at this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The reservation can't be seated from its current status.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/schedule": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the weekly opening hours of a restaurant. A weekday (0 is Sunday) can have several intervals, like lunch and dinner, and a closeTime before the openTime closes after midnight. Intervals can't overlap, also not when one runs past midnight into the next day's. Weekdays without intervals are closed. The time zone is changed too when one is given. Only the restaurant's owner and admins can edit the schedule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurants"
+                ],
+                "summary": "Replace the Weekly Schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Weekly Schedule",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The restaurant with its new schedule.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Restaurant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format, the fields that are wrong are listed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to edit this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Restaurant not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while saving the schedule.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/tables": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the tables of a restaurant with their seat counts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tables"
+                ],
+                "summary": "Get Restaurant Tables",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "An array of table objects.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Table"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching tables.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a table to the restaurant. Table numbers are unique per restaurant. Only the restaurant's owner and admins can manage tables.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tables"
+                ],
+                "summary": "Add a Table",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Table Details",
+                        "name": "table",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.TableRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created table.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Table"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format or restaurant ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage tables of this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A table with this number already exists.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while creating the table.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/tables/{tableId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the number or the seat count of a table. Reservations on the table get its new number. The seats can't be lowered below the party size of an upcoming reservation, a seated walk-in or a held waitlist offer on the table. Only the restaurant's owner and admins can manage tables.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tables"
+                ],
+                "summary": "Update a Table",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Table ID",
+                        "name": "tableId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Table Details",
+                        "name": "table",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.TableRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The updated table.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Table"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format, restaurant ID or table ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage tables of this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Table not found at this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A table with this number already exists, or upcoming reservations have more guests than the new seat count.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while updating the table.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a table from the restaurant. Tables with upcoming reservations or seated walk-ins can't be removed until those are moved or cancelled. Only the restaurant's owner and admins can manage tables.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tables"
+                ],
+                "summary": "Delete a Table",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Table ID",
+                        "name": "tableId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Table successfully deleted, no content to return."
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID or table ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage tables of this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Table not found at this restaurant.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The table still has upcoming reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while deleting the table.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{id}/waitlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists everyone waiting for a table at the restaurant, in the order tables get offered. Only available to admins and the restaurant's owner and staff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "Get a Restaurant's Waitlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The restaurant's waitlist.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WaitlistEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid restaurant ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this restaurant's waitlist.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching the waitlist.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Puts the current user on the waitlist for a time at a fully booked restaurant. When a booking that overlaps the time is cancelled or deleted, the first guest in line that fits gets a table held for them, is sent an email about it and has to confirm it at /waitlist/{id}/confirm before the hold runs out. A guest can only wait once for overlapping times at a restaurant.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "Join a Restaurant's Waitlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Restaurant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Wanted Time and Party Size",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.WaitlistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The waitlist entry with its position in the queue.",
+                        "schema": {
+                            "$ref": "#/definitions/models.WaitlistEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format, the fields that are wrong are listed.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The email address has not been verified yet.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A table is still free for this time, or the user is already waiting for an overlapping time.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while joining the waitlist.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/comments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of comments associated with a specific restaurant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Get Reataurant's Comments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reataurant ID",
+                        "name": "restaurantId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "An array of comment objects for the restaurant.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Comment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reataurant ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Comments not found for the specified restaurant ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of all users in the system. Admins see the full profiles, everyone else only sees names and avatars.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get All Users",
+                "responses": {
+                    "200": {
+                        "description": "An array of user objects.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PublicUser"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching users.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a new user to the system with the provided details.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create a New User",
+                "parameters": [
+                    {
+                        "description": "User Registration Details",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.CreateUserRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Mark the email as already verified",
+                        "name": "verified",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created user's details, including their unique identifier.",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format for user details, or the password is too short.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while creating the user.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves details of a single user by their unique identifier. Other users only see the name and avatar, the user themselves and admins see the full profile.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get a Single User",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The details of the user including ID, name, email, telephone, and role.",
+                        "schema": {
+                            "$ref": "#/definitions/models.OwnerUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the details of an existing user identified by their ID. Users can change their own name, email, telephone and avatar. Only admins can update other users or change a role or restaurant assignment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update a User",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated User Details",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The updated user's details.",
+                        "schema": {
+                            "$ref": "#/definitions/models.OwnerUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format for user details or invalid user ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to update this user or these fields.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email or telephone already belongs to another user.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while updating the user.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a user from the system by their unique identifier.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete a User",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User successfully deleted, no content to return."
+                    },
+                    "400": {
+                        "description": "Invalid user ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while deleting the user.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reservations.ics": {
+            "get": {
+                "description": "Returns the user's reservations from the last 90 days on as an iCalendar feed. Authenticated by the secret token from /me/calendar-feed instead of a bearer token, so calendar apps can poll it. Cancelled and deleted reservations stay in the feed as cancelled events.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Get a User's Calendar Feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Feed Token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reservations as a VCALENDAR.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No feed with this id and token.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error, unable to process the request.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/reservations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of reservations associated with a specific user. Users can only list their own reservations, admins can list anyone's.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get User's Reservations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "An array of reservation objects for the user.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Reservation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to see this user's reservations.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservations not found for the specified user ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the waitlist entries of the current user that are still waiting or have a table on hold, with their position in the queue.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "Get My Waitlist Entries",
+                "responses": {
+                    "200": {
+                        "description": "The user's waitlist entries.",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WaitlistEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while fetching the waitlist.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Takes the entry off the waitlist. A table held for it goes to the next guest in line. The guest, the restaurant's staff and admins can do this.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "Leave a Waitlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Waitlist Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Left the waitlist, no content to return."
+                    },
+                    "400": {
+                        "description": "Invalid waitlist ID format.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to remove this waitlist entry.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Waitlist entry not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The entry is no longer on the waitlist.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while leaving the waitlist.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist/{id}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Books the table that is held for the waitlist entry. Only the guest on the waitlist can confirm, and only before the hold runs out. The booking is checked against the current opening hours and booking policy like any other.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waitlist"
+                ],
+                "summary": "Confirm a Waitlist Offer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Waitlist Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The new reservation.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid waitlist ID format, or the booking is no longer within the opening hours or the booking policy.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ValidationErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not your waitlist entry, you already have as many active reservations as the booking policy allows, or you are restricted after too many no-shows.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Waitlist entry not found with the specified ID.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "There is no table on hold for this entry.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "The hold has run out.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while booking.",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ClearLockoutDetails": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                }
+            }
+        },
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Error message"
+                }
+            }
+        },
+        "api.ForgotPasswordDetails": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "api.LockoutsResponse": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.Lockout"
+                    }
+                },
+                "failedAttempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LoginAttempt"
+                    }
+                },
+                "ips": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.Lockout"
+                    }
+                }
+            }
+        },
+        "api.LoginDetails": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "api.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expiresIn": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Login successful"
+                },
+                "mfaRequired": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "mfaToken": {
+                    "type": "string",
+                    "example": ""
+                },
+                "refreshToken": {
+                    "type": "string",
+                    "example": ""
+                },
+                "token": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "api.MFACodeDetails": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "recoveryCode": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "api.MFAConfirmResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Two-factor authentication enabled"
+                },
+                "recoveryCodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abcd-efgh"
+                    ]
+                },
+                "token": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "api.MFAEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "otpauthUrl": {
+                    "type": "string",
+                    "example": "otpauth://totp/RedRice:user@example.com?secret=JBSWY3DPEHPK3PXP\u0026issuer=RedRice"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "api.MFAVerifyDetails": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfaToken": {
+                    "type": "string",
+                    "example": ""
+                },
+                "recoveryCode": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "api.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Logged out successfully"
+                }
+            }
+        },
+        "api.RefreshDetails": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "api.RegisterDetails": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "securePassword123"
+                },
+                "telephone": {
+                    "type": "string",
+                    "example": "123-456-7890"
+                }
+            }
+        },
+        "api.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "expiresIn": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "message": {
+                    "type": "string",
+                    "example": "User registered successfully, please check your email to verify your account"
+                },
+                "refreshToken": {
+                    "type": "string",
+                    "example": ""
+                },
+                "token": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "api.ResetPasswordDetails": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "newSecurePassword123"
+                },
+                "token": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "api.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "expiresIn": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "refreshToken": {
+                    "type": "string",
+                    "example": ""
+                },
+                "token": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "api.VerifyEmailDetails": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "middleware.Lockout": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "lockedUntil": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AdminUser": {
+            "type": "object",
+            "properties": {
+                "ID": {
+                    "type": "integer"
+                },
+                "avatarUrl": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "emailVerifiedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "noShowCount": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "telephone": {
+                    "type": "string"
+                },
+                "totpEnabledAt": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingPolicy": {
+            "type": "object",
+            "properties": {
+                "cancellationHours": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maxActiveBookings": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "maxDaysInAdvance": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "maxPartySize": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "minLeadMinutes": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "noShowLimit": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "noShowPeriodDays": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "restaurantId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Comment": {
+            "type": "object",
+            "properties": {
+                "dateTime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "myComment": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "restaurant": {
+                    "$ref": "#/definitions/models.Restaurant"
+                },
+                "restaurantId": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DayView": {
+            "type": "object",
+            "properties": {
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Reservation"
+                    }
+                },
+                "byStatus": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "covers": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "hours": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HourCovers"
+                    }
+                },
+                "openMinutes": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "reservations": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TableOccupancy"
+                    }
+                },
+                "timeZone": {
+                    "type": "string",
+                    "example": "Asia/Bangkok"
+                }
+            }
+        },
+        "models.EffectivePolicy": {
+            "type": "object",
+            "properties": {
+                "cancellationHours": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "maxActiveBookings": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "maxDaysInAdvance": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "maxPartySize": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "minLeadMinutes": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "noShowLimit": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "noShowPeriodDays": {
+                    "type": "integer",
+                    "example": 90
+                }
+            }
+        },
+        "models.HistoryAction": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "status_changed",
+                "deleted"
+            ],
+            "x-enum-varnames": [
+                "HistoryCreated",
+                "HistoryUpdated",
+                "HistoryStatusChanged",
+                "HistoryDeleted"
+            ]
+        },
+        "models.HourCovers": {
+            "type": "object",
+            "properties": {
+                "covers": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "hour": {
+                    "type": "string"
+                },
+                "reservations": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "models.LoginAttempt": {
+            "type": "object",
+            "properties": {
+                "attemptAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.OccurrenceConflict": {
+            "type": "object",
+            "properties": {
+                "conflict": {
+                    "$ref": "#/definitions/models.ReservationConflictError"
+                },
+                "dateTime": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "table 4 is already booked from 2024-05-03T12:00:00Z to 2024-05-03T14:00:00Z"
+                },
+                "exitTime": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reservationId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OpeningException": {
+            "type": "object",
+            "properties": {
+                "closeTime": {
+                    "type": "string",
+                    "example": "02:00"
+                },
+                "closed": {
+                    "type": "boolean"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "New Year's Eve"
+                },
+                "openTime": {
+                    "type": "string",
+                    "example": "18:00"
+                },
+                "restaurantId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OpeningInterval": {
+            "type": "object",
+            "properties": {
+                "closeTime": {
+                    "type": "string",
+                    "example": "14:00"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "openTime": {
+                    "type": "string",
+                    "example": "11:00"
+                },
+                "restaurantId": {
+                    "type": "integer"
+                },
+                "weekday": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.OwnerUser": {
+            "type": "object",
+            "properties": {
+                "ID": {
+                    "type": "integer"
+                },
+                "avatarUrl": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "emailVerifiedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "noShowCount": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "telephone": {
+                    "type": "string"
+                },
+                "totpEnabledAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PublicUser": {
+            "type": "object",
+            "properties": {
+                "ID": {
+                    "type": "integer"
+                },
+                "avatarUrl": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Reservation": {
+            "type": "object",
+            "properties": {
+                "dateTime": {
+                    "type": "string"
+                },
+                "dietaryFlags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vegetarian",
+                        "nut_allergy"
+                    ]
+                },
+                "exitTime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "partySize": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "restaurant": {
+                    "$ref": "#/definitions/models.Restaurant"
+                },
+                "restaurantId": {
+                    "type": "integer"
+                },
+                "seriesId": {
+                    "type": "integer"
+                },
+                "specialRequests": {
+                    "type": "string",
+                    "example": "High chair please, it's a birthday"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.ReservationStatus"
+                },
+                "statusChangedAt": {
+                    "type": "string"
+                },
+                "statusChangedById": {
+                    "type": "integer"
+                },
+                "tableId": {
+                    "type": "integer"
+                },
+                "tableNum": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReservationConflictError": {
+            "type": "object",
+            "properties": {
+                "dateTime": {
+                    "type": "string"
+                },
+                "exitTime": {
+                    "type": "string"
+                },
+                "tableNum": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReservationHistory": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HistoryAction"
+                        }
+                    ],
+                    "example": "updated"
+                },
+                "actor": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "actorId": {
+                    "type": "integer"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reservationId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReservationSeries": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "dateTime": {
+                    "type": "string"
+                },
+                "dietaryFlags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vegetarian"
+                    ]
+                },
+                "exitTime": {
+                    "type": "string"
+                },
+                "frequency": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SeriesFrequency"
+                        }
+                    ],
+                    "example": "weekly"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "partySize": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "reservations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Reservation"
+                    }
+                },
+                "restaurant": {
+                    "$ref": "#/definitions/models.Restaurant"
+                },
+                "restaurantId": {
+                    "type": "integer"
+                },
+                "specialRequests": {
+                    "type": "string",
+                    "example": "Quiet corner for a team lunch"
+                },
+                "tableNum": {
+                    "type": "integer"
+                },
+                "until": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReservationStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "confirmed",
+                "seated",
+                "completed",
+                "cancelled",
+                "no_show"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusConfirmed",
+                "StatusSeated",
+                "StatusCompleted",
+                "StatusCancelled",
+                "StatusNoShow"
+            ]
+        },
+        "models.ReservationStatusChange": {
+            "type": "object",
+            "properties": {
+                "changedAt": {
+                    "type": "string"
+                },
+                "changedById": {
+                    "type": "integer"
+                },
+                "fromStatus": {
+                    "$ref": "#/definitions/models.ReservationStatus"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reservationId": {
+                    "type": "integer"
+                },
+                "toStatus": {
+                    "$ref": "#/definitions/models.ReservationStatus"
+                }
+            }
+        },
+        "models.Restaurant": {
+            "type": "object",
+            "required": [
+                "commentCount",
+                "rating"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "closeTime": {
+                    "type": "string"
+                },
+                "commentCount": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "description": {
+                    "type": "string"
+                },
+                "exceptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OpeningException"
+                    }
+                },
+                "facebook": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imageUrl": {
+                    "type": "string"
+                },
+                "instagram": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "openNow": {
+                    "type": "boolean"
+                },
+                "openTime": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "schedule": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OpeningInterval"
+                    }
+                },
+                "telephone": {
+                    "type": "string"
+                },
+                "timeZone": {
+                    "type": "string",
+                    "example": "Asia/Bangkok"
+                }
+            }
+        },
+        "models.RestaurantAvailability": {
+            "type": "object",
+            "properties": {
+                "restaurant": {
+                    "$ref": "#/definitions/models.Restaurant"
+                },
+                "tablesLeft": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SeriesFrequency": {
+            "type": "string",
+            "enum": [
+                "weekly",
+                "monthly"
+            ],
+            "x-enum-varnames": [
+                "SeriesWeekly",
+                "SeriesMonthly"
+            ]
+        },
+        "models.Slot": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "tablesLeft": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Table": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "restaurantId": {
+                    "type": "integer"
+                },
+                "seats": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TableOccupancy": {
+            "type": "object",
+            "properties": {
+                "bookedMinutes": {
+                    "type": "integer",
+                    "example": 360
+                },
+                "covers": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "occupancy": {
+                    "type": "number",
+                    "example": 0.6
+                },
+                "reservations": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "seats": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "tableId": {
+                    "type": "integer"
+                },
+                "tableNum": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "avatarUrl": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "emailVerifiedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "noShowCount": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "telephone": {
+                    "type": "string"
+                },
+                "totpEnabledAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WaitlistEntry": {
+            "type": "object",
+            "properties": {
+                "dateTime": {
+                    "type": "string"
+                },
+                "exitTime": {
+                    "type": "string"
+                },
+                "holdExpiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "partySize": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "reservationId": {
+                    "type": "integer"
+                },
+                "restaurant": {
+                    "$ref": "#/definitions/models.Restaurant"
+                },
+                "restaurantId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WaitlistStatus"
+                },
+                "tableId": {
+                    "type": "integer"
+                },
+                "tableNum": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WaitlistStatus": {
+            "type": "string",
+            "enum": [
+                "waiting",
+                "offered",
+                "booked",
+                "expired",
+                "left"
+            ],
+            "x-enum-varnames": [
+                "WaitlistWaiting",
+                "WaitlistOffered",
+                "WaitlistBooked",
+                "WaitlistExpired",
+                "WaitlistLeft"
+            ]
+        },
+        "models.WalkIn": {
+            "type": "object",
+            "properties": {
+                "calledAt": {
+                    "type": "string"
+                },
+                "estimatedWaitMinutes": {
+                    "type": "integer"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "leaveAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Somchai"
+                },
+                "partySize": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "queueDate": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "restaurantId": {
+                    "type": "integer"
+                },
+                "seatedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WalkInStatus"
+                },
+                "tableId": {
+                    "type": "integer"
+                },
+                "tableNum": {
+                    "type": "integer"
+                },
+                "ticketNumber": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WalkInStatus": {
+            "type": "string",
+            "enum": [
+                "waiting",
+                "called",
+                "seated",
+                "finished",
+                "skipped",
+                "left"
+            ],
+            "x-enum-varnames": [
+                "WalkInWaiting",
+                "WalkInCalled",
+                "WalkInSeated",
+                "WalkInFinished",
+                "WalkInSkipped",
+                "WalkInLeft"
+            ]
+        },
+        "v1.AvailabilityResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "partySize": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "restaurantId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Slot"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.ErrorResponse": {
+        },
+        "v1.AvailabilitySearchResponse": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "Error message"
+                "dateTime": {
+                    "type": "string"
+                },
+                "exitTime": {
+                    "type": "string"
+                },
+                "partySize": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "restaurants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RestaurantAvailability"
+                    }
                 }
             }
         },
-        "api.LoginDetails": {
+        "v1.BookingPolicyRequest": {
             "type": "object",
             "properties": {
-                "email": {
+                "cancellationHours": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "maxActiveBookings": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "maxDaysInAdvance": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "maxPartySize": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "minLeadMinutes": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "noShowLimit": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "noShowPeriodDays": {
+                    "type": "integer",
+                    "example": 90
+                }
+            }
+        },
+        "v1.BookingPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "effective": {
+                    "$ref": "#/definitions/models.EffectivePolicy"
+                },
+                "policy": {
+                    "$ref": "#/definitions/models.BookingPolicy"
+                }
+            }
+        },
+        "v1.CalendarFeedResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
                     "type": "string",
-                    "example": "user@example.com"
+                    "example": "abc"
                 },
-                "password": {
+                "url": {
                     "type": "string",
-                    "example": "password123"
+                    "example": "https://api.redrice.app/api/v1/users/1/reservations.ics?token=abc"
                 }
             }
         },
-        "api.LoginResponse": {
+        "v1.ChangePasswordRequest": {
             "type": "object",
             "properties": {
-                "message": {
+                "currentPassword": {
                     "type": "string",
-                    "example": "Login successful"
+                    "example": "securePassword123"
                 },
+                "newPassword": {
+                    "type": "string",
+                    "example": "evenMoreSecurePassword456"
+                }
+            }
+        },
+        "v1.CheckInDetails": {
+            "type": "object",
+            "properties": {
                 "token": {
                     "type": "string",
-                    "example": ""
+                    "example": "AAAAAQAAAAIAAAAAZjJ7YNc1..."
                 }
             }
         },
-        "api.RegisterDetails": {
+        "v1.ConflictResponse": {
+            "type": "object",
+            "properties": {
+                "conflict": {
+                    "$ref": "#/definitions/models.ReservationConflictError"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "table 4 is already booked from 2024-05-01T18:00:00Z to 2024-05-01T20:00:00Z"
+                }
+            }
+        },
+        "v1.CreateUserRequest": {
             "type": "object",
             "properties": {
                 "email": {
@@ -1218,11 +6131,11 @@ const docTemplate = `{
                 },
                 "restaurant_id": {
                     "type": "integer",
-                    "example": 0
+                    "example": 1
                 },
                 "role": {
                     "type": "string",
-                    "example": "user"
+                    "example": "restaurant_owner"
                 },
                 "telephone": {
                     "type": "string",
@@ -1230,152 +6143,203 @@ const docTemplate = `{
                 }
             }
         },
-        "api.RegisterResponse": {
+        "v1.ErrorResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "error": {
                     "type": "string",
-                    "example": "User registered successfully"
+                    "example": "Description of the error occurred"
                 }
             }
         },
-        "models.Comment": {
+        "v1.ExceptionRequest": {
             "type": "object",
             "properties": {
-                "dateTime": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "myComment": {
-                    "type": "string"
-                },
-                "rating": {
-                    "type": "number"
+                "closeTime": {
+                    "type": "string",
+                    "example": "02:00"
                 },
-                "restaurant": {
-                    "$ref": "#/definitions/models.Restaurant"
+                "closed": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "restaurantId": {
-                    "type": "integer"
+                "date": {
+                    "type": "string",
+                    "example": "2024-12-31"
                 },
-                "user": {
-                    "$ref": "#/definitions/models.User"
+                "note": {
+                    "type": "string",
+                    "example": "New Year's Eve"
                 },
-                "userId": {
-                    "type": "integer"
+                "openTime": {
+                    "type": "string",
+                    "example": "18:00"
                 }
             }
         },
-        "models.Reservation": {
+        "v1.JoinQueueRequest": {
             "type": "object",
             "properties": {
-                "dateTime": {
-                    "type": "string"
-                },
-                "exitTime": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "restaurant": {
-                    "$ref": "#/definitions/models.Restaurant"
-                },
-                "restaurantId": {
-                    "type": "integer"
-                },
-                "tableNum": {
-                    "type": "integer"
-                },
-                "user": {
-                    "$ref": "#/definitions/models.User"
+                "name": {
+                    "type": "string",
+                    "example": "Somchai"
                 },
-                "userId": {
-                    "type": "integer"
+                "partySize": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "models.Restaurant": {
+        "v1.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Done successfully"
+                }
+            }
+        },
+        "v1.ScheduleInterval": {
             "type": "object",
-            "required": [
-                "commentCount",
-                "rating"
-            ],
             "properties": {
-                "address": {
-                    "type": "string"
-                },
                 "closeTime": {
-                    "type": "string"
-                },
-                "commentCount": {
-                    "type": "number",
-                    "minimum": 0
-                },
-                "description": {
-                    "type": "string"
-                },
-                "facebook": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
+                    "type": "string",
+                    "example": "14:00"
                 },
-                "imageUrl": {
-                    "type": "string"
+                "openTime": {
+                    "type": "string",
+                    "example": "11:00"
                 },
-                "instagram": {
-                    "type": "string"
+                "weekday": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "v1.ScheduleRequest": {
+            "type": "object",
+            "properties": {
+                "intervals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.ScheduleInterval"
+                    }
                 },
-                "name": {
-                    "type": "string"
+                "timeZone": {
+                    "type": "string",
+                    "example": "Asia/Bangkok"
+                }
+            }
+        },
+        "v1.SeatWalkInRequest": {
+            "type": "object",
+            "properties": {
+                "tableNum": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "v1.SeriesConflictResponse": {
+            "type": "object",
+            "properties": {
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OccurrenceConflict"
+                    }
                 },
-                "openTime": {
-                    "type": "string"
+                "error": {
+                    "type": "string",
+                    "example": "none of the occurrences could be booked"
+                }
+            }
+        },
+        "v1.SeriesResponse": {
+            "type": "object",
+            "properties": {
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OccurrenceConflict"
+                    }
                 },
-                "rating": {
-                    "type": "number",
-                    "minimum": 0
+                "series": {
+                    "$ref": "#/definitions/models.ReservationSeries"
+                }
+            }
+        },
+        "v1.TableRequest": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "type": "integer",
+                    "example": 4
                 },
-                "telephone": {
-                    "type": "string"
+                "seats": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "models.User": {
+        "v1.UpdateUserRequest": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
+                "avatarUrl": {
+                    "type": "string",
+                    "example": "https://example.com/avatar.png"
                 },
-                "id": {
-                    "type": "integer"
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
                 },
                 "name": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "John Doe"
                 },
                 "restaurant_id": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 0
                 },
                 "role": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "user"
                 },
                 "telephone": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "123-456-7890"
                 }
             }
         },
-        "v1.ErrorResponse": {
+        "v1.ValidationErrorResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string",
-                    "example": "Description of the error occurred"
+                    "example": "dateTime must be in the future"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "v1.WaitlistRequest": {
+            "type": "object",
+            "properties": {
+                "dateTime": {
+                    "type": "string",
+                    "example": "2024-05-01T18:00:00+07:00"
+                },
+                "exitTime": {
+                    "type": "string",
+                    "example": "2024-05-01T20:00:00+07:00"
+                },
+                "partySize": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         }
@@ -9,12 +9,15 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // restaurant time zones work even when the image has no zoneinfo
 
 	"github.com/joho/godotenv"
 	config "github.com/punchanabu/redrice-backend-go/config"
+	"github.com/punchanabu/redrice-backend-go/middleware"
 	routers "github.com/punchanabu/redrice-backend-go/routers"
 	"github.com/punchanabu/redrice-backend-go/routers/api"
 	v1 "github.com/punchanabu/redrice-backend-go/routers/api/v1"
+	"github.com/punchanabu/redrice-backend-go/utils"
 )
 
 func main() {
@@ -26,6 +29,20 @@ func main() {
 		log.Println("Error loading .env file")
 	}
 
+	// Load the keys tokens are signed with
+	if err := middleware.InitializedJWTKeys(); err != nil {
+		log.Fatal("Failed to load JWT keys: ", err)
+	}
+	if err := middleware.InitializedCheckInSecret(); err != nil {
+		log.Fatal("Failed to load check-in secret: ", err)
+	}
+
+	// Setup how mails are sent
+	mailer, err := utils.NewMailerFromEnv()
+	if err != nil {
+		log.Fatal("Failed to set up mail: ", err)
+	}
+
 	// Setup database connection
 	db := config.SetupDBConnection()
 	if db == nil {
@@ -36,8 +53,15 @@ func main() {
 	v1.InitializedUserHandler(db)
 	v1.InitializedRestaurantHandler(db)
 	api.InitializedAuthHandler(db)
+	api.SetMailer(mailer)
 	v1.InitializedReservationHandler(db)
 	v1.InitializedCommentHandler(db)
+	v1.InitializedTableHandler(db)
+	v1.InitializedWaitlistHandler(db)
+	v1.SetWaitlistMailer(mailer)
+	v1.InitializedBookingPolicyHandler(db)
+	v1.InitializedWalkInHandler(db)
+	middleware.InitializedAuthMiddleware(db)
 
 	// Initialize router
 	// @securityDefinitions.apikey BearerAuth
@@ -50,6 +74,11 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// Pass on tables held for waitlist guests who didn't confirm in time
+	go v1.ExpireWaitlistOffers(ctx, time.Minute)
+	// Let waitlist guests know a table is held for them
+	go v1.NotifyWaitlistOffers(ctx, 10*time.Second)
+
 	server := &http.Server{
 		Addr:    ":" + os.Getenv("PORT"),
 		Handler: r,
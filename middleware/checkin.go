@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Check-in tokens are the reservation, its restaurant and its start time sealed with AES-GCM,
+// so guests can't read or change what's in them, base64 encoded. Short enough to make a QR code
+// phones can show and scanners can read.
+const checkInPayloadLength = 16
+
+var ErrInvalidCheckInToken = errors.New("check-in code is not valid")
+
+var checkInAEAD cipher.AEAD
+
+type CheckInClaims struct {
+	ReservationID uint
+	RestaurantID  uint
+	DateTime      time.Time
+}
+
+// Load the secret check-in tokens are sealed with from CHECKIN_TOKEN_SECRET
+func InitializedCheckInSecret() error {
+	secret := os.Getenv("CHECKIN_TOKEN_SECRET")
+	if len(secret) < 32 {
+		return fmt.Errorf("CHECKIN_TOKEN_SECRET must be at least 32 characters, generate one with `openssl rand -hex 32`")
+	}
+
+	// The secret can be any length, the key is derived from it for this one purpose
+	key := sha256.Sum256([]byte("redrice check-in token\x00" + secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return err
+	}
+	checkInAEAD, err = cipher.NewGCM(block)
+	return err
+}
+
+func GenerateCheckInToken(claims CheckInClaims) (string, error) {
+	payload := make([]byte, checkInPayloadLength)
+	binary.BigEndian.PutUint32(payload[0:4], uint32(claims.ReservationID))
+	binary.BigEndian.PutUint32(payload[4:8], uint32(claims.RestaurantID))
+	binary.BigEndian.PutUint64(payload[8:16], uint64(claims.DateTime.Unix()))
+
+	nonce := make([]byte, checkInAEAD.NonceSize(), checkInAEAD.NonceSize()+checkInPayloadLength+checkInAEAD.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(checkInAEAD.Seal(nonce, nonce, payload, nil)), nil
+}
+
+// Open a check-in token and read what's in it
+func ValidateCheckInToken(token string) (*CheckInClaims, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) != checkInAEAD.NonceSize()+checkInPayloadLength+checkInAEAD.Overhead() {
+		return nil, ErrInvalidCheckInToken
+	}
+
+	nonce, sealed := b[:checkInAEAD.NonceSize()], b[checkInAEAD.NonceSize():]
+	payload, err := checkInAEAD.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrInvalidCheckInToken
+	}
+
+	return &CheckInClaims{
+		ReservationID: uint(binary.BigEndian.Uint32(payload[0:4])),
+		RestaurantID:  uint(binary.BigEndian.Uint32(payload[4:8])),
+		DateTime:      time.Unix(int64(binary.BigEndian.Uint64(payload[8:16])), 0),
+	}, nil
+}
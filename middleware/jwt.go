@@ -1,54 +1,154 @@
 package middleware
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/punchanabu/redrice-backend-go/models"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET"))
+// Access tokens are short-lived, clients use their refresh token to get a new one
+const AccessTokenTTL = 15 * time.Minute
+
+// MFA challenge tokens only live long enough to type in a code
+const MFATokenTTL = 5 * time.Minute
+
+const purposeMFAChallenge = "mfa_challenge"
 
 type Claims struct {
-	UserId uint `json:"id"`
-	Email string `json:"email"`
-	Role string `json:"role"`
+	UserId       uint   `json:"id"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	RestaurantId uint   `json:"restaurantId,omitempty"`
+	SessionId    uint   `json:"sid"`
+	MFA          bool   `json:"mfa"`
+	Purpose      string `json:"purpose,omitempty"`
 	jwt.StandardClaims
 }
 
-// Generate Token for a given email ✨
-func GenerateToken(email string, userId uint, role string) (string, error) {
+// Who issues RedRice tokens and who they are meant for, other services check these too
+func issuer() string {
+	if iss := os.Getenv("JWT_ISSUER"); iss != "" {
+		return iss
+	}
+	return "redrice"
+}
+
+func audience() string {
+	if aud := os.Getenv("JWT_AUDIENCE"); aud != "" {
+		return aud
+	}
+	return "redrice-api"
+}
+
+func signClaims(claims *Claims) (string, error) {
+	if currentSigningKey == nil {
+		return "", fmt.Errorf("no JWT signing key loaded")
+	}
+
+	claims.Issuer = issuer()
+	claims.Audience = audience()
+	claims.IssuedAt = time.Now().Unix()
+
+	token := jwt.NewWithClaims(currentSigningKey.method, claims)
+	token.Header["kid"] = currentSigningKey.kid
+	return token.SignedString(currentSigningKey.private)
+}
+
+func parseClaims(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyForToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, jwt.NewValidationError("Invalid Token", jwt.ValidationErrorMalformed)
+	}
+
+	if !claims.VerifyIssuer(issuer(), true) {
+		return nil, jwt.NewValidationError("Invalid Issuer", jwt.ValidationErrorIssuer)
+	}
+
+	if !claims.VerifyAudience(audience(), true) {
+		return nil, jwt.NewValidationError("Invalid Audience", jwt.ValidationErrorAudience)
+	}
+
+	return claims, nil
+}
+
+// Generate Token for a user's session ✨
+func GenerateToken(user *models.User, session *models.Session) (string, error) {
 
-	exprTime := time.Now().Add(24 * time.Hour)
+	exprTime := time.Now().Add(AccessTokenTTL)
 	claims := &Claims{
-		Email: email,
-		UserId: userId,
-		Role: role,
+		Email:        user.Email,
+		UserId:       user.ID,
+		Role:         user.Role,
+		RestaurantId: user.RestaurantId,
+		SessionId:    session.ID,
+		MFA:          session.MFAVerified,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: exprTime.Unix(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
-
-	return tokenString, err
+	return signClaims(claims)
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
-	claims := &Claims{}
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// MFA challenge tokens must never work as access tokens
+	if claims.Purpose != "" {
+		return nil, jwt.NewValidationError("Invalid Token", jwt.ValidationErrorClaimsInvalid)
+	}
+
+	return claims, nil
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+// Generate a short-lived token proving the password step of the login was passed
+func GenerateMFAToken(user *models.User) (string, error) {
+	claims := &Claims{
+		Email:   user.Email,
+		UserId:  user.ID,
+		Role:    user.Role,
+		Purpose: purposeMFAChallenge,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(MFATokenTTL).Unix(),
+		},
+	}
 
+	return signClaims(claims)
+}
+
+func ValidateMFAToken(tokenString string) (*Claims, error) {
+	claims, err := parseClaims(tokenString)
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
-		return nil, jwt.NewValidationError("Invalid Token", jwt.ValidationErrorMalformed)
+	if claims.Purpose != purposeMFAChallenge {
+		return nil, jwt.NewValidationError("Invalid Token", jwt.ValidationErrorClaimsInvalid)
 	}
 
 	return claims, nil
 }
+
+// Roles listed in MFA_REQUIRED_ROLES (comma separated) must use two-factor authentication
+func MFARequiredForRole(role string) bool {
+	for _, r := range strings.Split(os.Getenv("MFA_REQUIRED_ROLES"), ",") {
+		if strings.TrimSpace(r) == role && role != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Public key that tokens can be verified with, published in the JWKS
+type verifyKey struct {
+	kid    string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// Private key new tokens are signed with
+type signingKey struct {
+	verifyKey
+	private crypto.Signer
+}
+
+// A single entry of /.well-known/jwks.json, see RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var currentSigningKey *signingKey
+var verifyKeys = map[string]*verifyKey{}
+
+// Load the signing key from JWT_SIGNING_KEY_FILE and any older keys that should still be
+// accepted from JWT_VERIFY_KEY_FILES (comma separated). Keys are PEM encoded RSA or Ed25519 keys.
+func InitializedJWTKeys() error {
+	path := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if path == "" {
+		return fmt.Errorf("JWT_SIGNING_KEY_FILE is not set, generate a key with `openssl genpkey -algorithm ed25519 -out jwt.pem`")
+	}
+
+	private, public, err := loadKeyFile(path)
+	if err != nil {
+		return err
+	}
+	if private == nil {
+		return fmt.Errorf("%s does not contain a private key", path)
+	}
+
+	key, err := newVerifyKey(public)
+	if err != nil {
+		return err
+	}
+
+	currentSigningKey = &signingKey{verifyKey: *key, private: private}
+	verifyKeys = map[string]*verifyKey{key.kid: key}
+
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFY_KEY_FILES"), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		_, public, err := loadKeyFile(path)
+		if err != nil {
+			return err
+		}
+
+		key, err := newVerifyKey(public)
+		if err != nil {
+			return err
+		}
+		verifyKeys[key.kid] = key
+	}
+
+	return nil
+}
+
+func loadKeyFile(path string) (crypto.Signer, crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, signer.Public(), nil
+		}
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, key.Public(), nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return nil, key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return nil, key, nil
+	}
+
+	return nil, nil, fmt.Errorf("%s does not contain a supported key", path)
+}
+
+func newVerifyKey(public crypto.PublicKey) (*verifyKey, error) {
+	var method jwt.SigningMethod
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("only RSA and Ed25519 keys are supported")
+	}
+
+	key := &verifyKey{method: method, public: public}
+	key.kid = thumbprint(key.jwk())
+	return key, nil
+}
+
+func (k *verifyKey) jwk() JWK {
+	jwk := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+	switch key := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	}
+	return jwk
+}
+
+// JWK thumbprint from RFC 7638, used as the kid so it stays the same for the same key
+func thumbprint(jwk JWK) string {
+	var members string
+	if jwk.Kty == "RSA" {
+		members = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	} else {
+		members = fmt.Sprintf(`{"crv":"%s","kty":"%s","x":"%s"}`, jwk.Crv, jwk.Kty, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Every key tokens may currently be verified with, for other services to fetch
+func PublicJWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range verifyKeys {
+		jwks.Keys = append(jwks.Keys, key.jwk())
+	}
+	return jwks
+}
+
+// Look up the verification key by the token's kid, and make sure the token uses that key's algorithm
+func keyForToken(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.public, nil
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+func TestThumbprint(t *testing.T) {
+	tests := []struct {
+		name string
+		jwk  JWK
+		want string
+	}{
+		{
+			// RFC 7638 section 3.1
+			name: "rsa",
+			jwk: JWK{
+				Kty: "RSA",
+				N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+				E:   "AQAB",
+			},
+			want: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			// RFC 8037 appendix A.3
+			name: "ed25519",
+			jwk:  JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+			want: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		},
+		{
+			// Only the required members count
+			name: "ignores kid, use and alg",
+			jwk:  JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo", Kid: "old", Use: "sig", Alg: "EdDSA"},
+			want: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thumbprint(tt.jwk); got != tt.want {
+				t.Errorf("thumbprint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewVerifyKey(t *testing.T) {
+	x, _ := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	key, err := newVerifyKey(ed25519.PublicKey(x))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.kid != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+		t.Errorf("kid = %s, want the RFC 8037 thumbprint", key.kid)
+	}
+	jwk := key.jwk()
+	if jwk.Kid != key.kid || jwk.Alg != "EdDSA" || jwk.Use != "sig" || jwk.X != "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo" {
+		t.Errorf("unexpected jwk %+v", jwk)
+	}
+
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newVerifyKey(&small.PublicKey); err == nil {
+		t.Error("accepted a 1024 bit RSA key")
+	}
+}
+
+// Write a fresh Ed25519 key as PKCS8 PEM and return its path
+func writeTestKey(t *testing.T) string {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.CreateTemp(t.TempDir(), "jwt-*.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
+func TestKeyRotation(t *testing.T) {
+	user := &models.User{ID: 1, Email: "somchai@example.com", Role: models.RoleUser}
+	session := &models.Session{ID: 1}
+
+	oldKey, newKey := writeTestKey(t), writeTestKey(t)
+	t.Setenv("JWT_SIGNING_KEY_FILE", oldKey)
+	t.Setenv("JWT_VERIFY_KEY_FILES", "")
+	if err := InitializedJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := GenerateToken(user, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKid := currentSigningKey.kid
+
+	// Rotate, the old key is only used to verify tokens that are still around
+	t.Setenv("JWT_SIGNING_KEY_FILE", newKey)
+	t.Setenv("JWT_VERIFY_KEY_FILES", oldKey)
+	if err := InitializedJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := GenerateToken(user, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := ValidateToken(token); err != nil {
+			t.Errorf("%s token doesn't validate: %v", name, err)
+		}
+	}
+
+	parsed, _, err := new(jwt.Parser).ParseUnverified(newToken, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != currentSigningKey.kid || currentSigningKey.kid == oldKid {
+		t.Errorf("new token has kid %v, want the new key's %s", parsed.Header["kid"], currentSigningKey.kid)
+	}
+
+	jwks := PublicJWKS()
+	if len(jwks.Keys) != 2 {
+		t.Errorf("JWKS has %d keys, want 2", len(jwks.Keys))
+	}
+
+	// Once the old key is dropped its tokens stop working
+	t.Setenv("JWT_VERIFY_KEY_FILES", "")
+	if err := InitializedJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateToken(oldToken); err == nil {
+		t.Error("token signed with a dropped key still validates")
+	}
+}
+
+func TestInitializedJWTKeysErrors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		signing string
+		verify  string
+	}{
+		{"no signing key", "", ""},
+		{"missing file", filepath.Join(dir, "missing.pem"), ""},
+		{"not pem", notPEM, ""},
+		{"bad verify key", writeTestKey(t), notPEM},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SIGNING_KEY_FILE", tt.signing)
+			t.Setenv("JWT_VERIFY_KEY_FILES", tt.verify)
+			if err := InitializedJWTKeys(); err == nil {
+				t.Error("InitializedJWTKeys() = nil, want an error")
+			}
+		})
+	}
+}
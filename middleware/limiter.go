@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// A key that is temporarily not allowed to log in
+type Lockout struct {
+	Key         string    `json:"key"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
+
+// Keeps track of failed logins per key (an ip or an account) to slow down password guessing
+type LoginLimiter interface {
+	// How long the key is still locked out for, zero when it may try again
+	LockedFor(key string) time.Duration
+	// Record a failed attempt and return how long the key is now locked out for
+	RecordFailure(key string) time.Duration
+	// Forget every failure and lockout of the key
+	Reset(key string)
+	// Every key that is currently locked out
+	Lockouts() []Lockout
+}
+
+// Sliding window limiter that lives in memory, good enough for a single server
+type MemoryLimiter struct {
+	mu          sync.Mutex
+	maxFailures int
+	window      time.Duration
+	lockout     time.Duration
+	failures    map[string][]time.Time
+	lockedUntil map[string]time.Time
+	lastSweep   time.Time
+}
+
+// Lock a key out for lockout after maxFailures failed attempts within window
+func NewMemoryLimiter(maxFailures int, window time.Duration, lockout time.Duration) *MemoryLimiter {
+	return &MemoryLimiter{
+		maxFailures: maxFailures,
+		window:      window,
+		lockout:     lockout,
+		failures:    map[string][]time.Time{},
+		lockedUntil: map[string]time.Time{},
+	}
+}
+
+func (l *MemoryLimiter) lockedFor(key string, now time.Time) time.Duration {
+	until, ok := l.lockedUntil[key]
+	if !ok {
+		return 0
+	}
+	if !now.Before(until) {
+		delete(l.lockedUntil, key)
+		return 0
+	}
+	return until.Sub(now)
+}
+
+func (l *MemoryLimiter) LockedFor(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lockedFor(key, time.Now())
+}
+
+func (l *MemoryLimiter) RecordFailure(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+	if wait := l.lockedFor(key, now); wait > 0 {
+		return wait
+	}
+
+	// Drop failures that slid out of the window
+	recent := l.failures[key][:0]
+	for _, t := range l.failures[key] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+
+	if len(recent) >= l.maxFailures {
+		delete(l.failures, key)
+		l.lockedUntil[key] = now.Add(l.lockout)
+		return l.lockout
+	}
+
+	l.failures[key] = recent
+	return 0
+}
+
+// Forget keys whose failures all slid out of the window and lockouts that ended, so keys that
+// are only tried a few times don't pile up. Runs at most once per window, mu must be held.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+
+	for key, failures := range l.failures {
+		if len(failures) == 0 || now.Sub(failures[len(failures)-1]) >= l.window {
+			delete(l.failures, key)
+		}
+	}
+	for key, until := range l.lockedUntil {
+		if !now.Before(until) {
+			delete(l.lockedUntil, key)
+		}
+	}
+}
+
+func (l *MemoryLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, key)
+	delete(l.lockedUntil, key)
+}
+
+func (l *MemoryLimiter) Lockouts() []Lockout {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	lockouts := []Lockout{}
+	for key := range l.lockedUntil {
+		if l.lockedFor(key, now) > 0 {
+			lockouts = append(lockouts, Lockout{Key: key, LockedUntil: l.lockedUntil[key]})
+		}
+	}
+	return lockouts
+}
@@ -2,78 +2,89 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"strings"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"gorm.io/gorm"
 )
 
-func Auth() gin.HandlerFunc {
-	return func(c *gin.Context) {
+var sessionHandler *models.SessionHandler
+var userHandler *models.UserHandler
 
-		authHeader := c.GetHeader("Authorization")
+func InitializedAuthMiddleware(db *gorm.DB) {
+	sessionHandler = models.NewSessionHandler(db)
+	userHandler = models.NewUserHandler(db)
+}
 
-		if authHeader == "" {
-		    c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is missing"})
-		    c.Abort()
-		    return
-		}
-		
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-		    c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer <token>"})
-		    c.Abort()
-		    return
-		}
+// Read the bearer token from the request and make sure its session is still alive
+func authenticate(c *gin.Context) (*Claims, bool) {
+	authHeader := c.GetHeader("Authorization")
 
-		tokenString := parts[1]
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is missing"})
+		c.Abort()
+		return nil, false
+	}
 
-		claims, err := ValidateToken(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized 🥹 Please login first!"})
-			c.Abort()
+	parts := strings.SplitN(authHeader, " ", 2)
+	if !(len(parts) == 2 && parts[0] == "Bearer") {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer <token>"})
+		c.Abort()
+		return nil, false
+	}
+
+	tokenString := parts[1]
+
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized 🥹 Please login first!"})
+		c.Abort()
+		return nil, false
+	}
+
+	if claims.SessionId == 0 || !sessionHandler.IsSessionActive(claims.SessionId) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked 🥹 Please login again!"})
+		c.Abort()
+		return nil, false
+	}
+
+	return claims, true
+}
+
+func Auth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := authenticate(c)
+		if !ok {
 			return
 		}
 
 		// Set user id to next handler for easy access
 		c.Set("id", claims.UserId)
+		c.Set("sessionId", claims.SessionId)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
 
-func Admin() gin.HandlerFunc {
+// Only let users who verified their email address through, must run after Auth()
+func Verified() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
+		id, _ := c.Get("id")
 
-		if authHeader == "" {
-		    c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is missing"})
-		    c.Abort()
-		    return
-		}
-		
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-		    c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer <token>"})
-		    c.Abort()
-		    return
-		}
-
-		tokenString := parts[1]
-
-		claims, err := ValidateToken(tokenString)
+		user, err := userHandler.GetUser(id.(uint))
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized 🥹 Please login first!"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			c.Abort()
 			return
 		}
 
-		if claims.Role != "admin" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized You are not an admin! 🥹 whahahahaha"})
+		if !user.IsEmailVerified() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address first 📧"})
 			c.Abort()
 			return
 		}
 
-		// Set user id to next handler for easy access
-		c.Set("id", claims.UserId)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
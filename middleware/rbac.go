@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+type Permission string
+
+const (
+	PermUsersManage                  Permission = "users:manage"
+	PermRestaurantsCreate            Permission = "restaurants:create"
+	PermRestaurantsUpdate            Permission = "restaurants:update"
+	PermRestaurantsDelete            Permission = "restaurants:delete"
+	PermRestaurantRatingsUpdate      Permission = "restaurants:ratings:update"
+	PermRestaurantReservationsRead   Permission = "restaurants:reservations:read"
+	PermRestaurantReservationsManage Permission = "restaurants:reservations:manage"
+	PermRestaurantTablesManage       Permission = "restaurants:tables:manage"
+	PermBookingPolicyManage          Permission = "booking-policy:manage"
+)
+
+type scope int
+
+const (
+	// The permission applies to every resource
+	scopeAny scope = iota + 1
+	// The permission only applies to the restaurant the user works at.
+	// Routes guarded by such a permission must have the restaurant id as :id
+	scopeOwnRestaurant
+)
+
+var rolePermissions = map[string]map[Permission]scope{
+	models.RoleAdmin: {
+		PermUsersManage:                  scopeAny,
+		PermRestaurantsCreate:            scopeAny,
+		PermRestaurantsUpdate:            scopeAny,
+		PermRestaurantsDelete:            scopeAny,
+		PermRestaurantRatingsUpdate:      scopeAny,
+		PermRestaurantReservationsRead:   scopeAny,
+		PermRestaurantReservationsManage: scopeAny,
+		PermRestaurantTablesManage:       scopeAny,
+		PermBookingPolicyManage:          scopeAny,
+	},
+	models.RoleRestaurantOwner: {
+		PermRestaurantsUpdate:            scopeOwnRestaurant,
+		PermRestaurantReservationsRead:   scopeOwnRestaurant,
+		PermRestaurantReservationsManage: scopeOwnRestaurant,
+		PermRestaurantTablesManage:       scopeOwnRestaurant,
+	},
+	models.RoleRestaurantStaff: {
+		PermRestaurantReservationsRead:   scopeOwnRestaurant,
+		PermRestaurantReservationsManage: scopeOwnRestaurant,
+	},
+	models.RoleUser: {},
+}
+
+// Whether the role has the permission for every resource, not just its own restaurant
+func HasGlobalPermission(role string, permission Permission) bool {
+	return rolePermissions[role][permission] == scopeAny
+}
+
+// Like HasGlobalPermission for the current session. Roles that must use two-factor authentication
+// only get the permission once the session passed it, the same as with Require.
+func HasGlobalAccess(claims *Claims, permission Permission) bool {
+	return HasGlobalPermission(claims.Role, permission) && mfaSatisfied(claims)
+}
+
+func mfaSatisfied(claims *Claims) bool {
+	return !MFARequiredForRole(claims.Role) || claims.MFA
+}
+
+// Whether the session may use the permission on the given restaurant
+func CanAccessRestaurant(claims *Claims, permission Permission, restaurantId uint) bool {
+	if !mfaSatisfied(claims) {
+		return false
+	}
+
+	switch rolePermissions[claims.Role][permission] {
+	case scopeAny:
+		return true
+	case scopeOwnRestaurant:
+		return claims.RestaurantId != 0 && claims.RestaurantId == restaurantId
+	}
+	return false
+}
+
+// Claims of the current request, set by Auth()
+func GetClaims(c *gin.Context) *Claims {
+	claims, ok := c.Get("claims")
+	if !ok {
+		return nil
+	}
+	return claims.(*Claims)
+}
+
+// Only let the request through when the user's role grants the permission, must run after Auth()
+func Require(permission Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetClaims(c)
+		if claims == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized 🥹 Please login first!"})
+			c.Abort()
+			return
+		}
+
+		granted, ok := rolePermissions[claims.Role][permission]
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this 🥹"})
+			c.Abort()
+			return
+		}
+
+		if !mfaSatisfied(claims) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for this account, please enroll and login again 🔐"})
+			c.Abort()
+			return
+		}
+
+		if granted == scopeOwnRestaurant {
+			restaurantId, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil || !CanAccessRestaurant(claims, permission, uint(restaurantId)) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "You can only do this for your own restaurant 🥹"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
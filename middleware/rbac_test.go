@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+var allPermissions = []Permission{
+	PermUsersManage,
+	PermRestaurantsCreate,
+	PermRestaurantsUpdate,
+	PermRestaurantsDelete,
+	PermRestaurantRatingsUpdate,
+	PermRestaurantReservationsRead,
+	PermRestaurantReservationsManage,
+	PermRestaurantTablesManage,
+	PermBookingPolicyManage,
+}
+
+// What every role may do: "any" for every restaurant, "own" for the one it works at, "" for nothing
+var permissionMatrix = map[string]map[Permission]string{
+	models.RoleAdmin: {
+		PermUsersManage:                  "any",
+		PermRestaurantsCreate:            "any",
+		PermRestaurantsUpdate:            "any",
+		PermRestaurantsDelete:            "any",
+		PermRestaurantRatingsUpdate:      "any",
+		PermRestaurantReservationsRead:   "any",
+		PermRestaurantReservationsManage: "any",
+		PermRestaurantTablesManage:       "any",
+		PermBookingPolicyManage:          "any",
+	},
+	models.RoleRestaurantOwner: {
+		PermRestaurantsUpdate:            "own",
+		PermRestaurantReservationsRead:   "own",
+		PermRestaurantReservationsManage: "own",
+		PermRestaurantTablesManage:       "own",
+	},
+	models.RoleRestaurantStaff: {
+		PermRestaurantReservationsRead:   "own",
+		PermRestaurantReservationsManage: "own",
+	},
+	models.RoleUser: {},
+	"unknown":       {},
+}
+
+const (
+	ownRestaurant   = 7
+	otherRestaurant = 8
+)
+
+func TestPermissionMatrix(t *testing.T) {
+	t.Setenv("MFA_REQUIRED_ROLES", "")
+
+	for role, granted := range permissionMatrix {
+		for _, permission := range allPermissions {
+			want := granted[permission]
+			t.Run(role+"/"+string(permission), func(t *testing.T) {
+				claims := &Claims{Role: role, RestaurantId: ownRestaurant}
+
+				if got := HasGlobalPermission(role, permission); got != (want == "any") {
+					t.Errorf("HasGlobalPermission() = %v", got)
+				}
+				if got := HasGlobalAccess(claims, permission); got != (want == "any") {
+					t.Errorf("HasGlobalAccess() = %v", got)
+				}
+				if got := CanAccessRestaurant(claims, permission, ownRestaurant); got != (want != "") {
+					t.Errorf("CanAccessRestaurant(own) = %v", got)
+				}
+				if got := CanAccessRestaurant(claims, permission, otherRestaurant); got != (want == "any") {
+					t.Errorf("CanAccessRestaurant(other) = %v", got)
+				}
+			})
+		}
+	}
+}
+
+// New permissions or roles have to be added to the matrix above
+func TestPermissionMatrixIsComplete(t *testing.T) {
+	known := map[Permission]bool{}
+	for _, permission := range allPermissions {
+		known[permission] = true
+	}
+	for role, permissions := range rolePermissions {
+		if _, ok := permissionMatrix[role]; !ok {
+			t.Errorf("role %s is missing from the matrix", role)
+		}
+		for permission := range permissions {
+			if !known[permission] {
+				t.Errorf("permission %s of %s is missing from the matrix", permission, role)
+			}
+		}
+	}
+}
+
+func TestCanAccessRestaurantWithoutRestaurant(t *testing.T) {
+	t.Setenv("MFA_REQUIRED_ROLES", "")
+
+	// Staff that isn't assigned to a restaurant yet can't use restaurant 0
+	claims := &Claims{Role: models.RoleRestaurantStaff}
+	if CanAccessRestaurant(claims, PermRestaurantReservationsRead, 0) {
+		t.Error("unassigned staff got access to restaurant 0")
+	}
+}
+
+func TestPermissionsNeedMFA(t *testing.T) {
+	t.Setenv("MFA_REQUIRED_ROLES", "admin, restaurant_owner")
+
+	tests := []struct {
+		name   string
+		claims Claims
+		want   bool
+	}{
+		{"admin without mfa", Claims{Role: models.RoleAdmin}, false},
+		{"admin with mfa", Claims{Role: models.RoleAdmin, MFA: true}, true},
+		{"owner without mfa", Claims{Role: models.RoleRestaurantOwner, RestaurantId: ownRestaurant}, false},
+		{"owner with mfa", Claims{Role: models.RoleRestaurantOwner, RestaurantId: ownRestaurant, MFA: true}, true},
+		{"staff doesn't need it", Claims{Role: models.RoleRestaurantStaff, RestaurantId: ownRestaurant}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanAccessRestaurant(&tt.claims, PermRestaurantReservationsRead, ownRestaurant); got != tt.want {
+				t.Errorf("CanAccessRestaurant() = %v, want %v", got, tt.want)
+			}
+			if tt.claims.Role == models.RoleAdmin {
+				if got := HasGlobalAccess(&tt.claims, PermUsersManage); got != tt.want {
+					t.Errorf("HasGlobalAccess() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	t.Setenv("MFA_REQUIRED_ROLES", "admin")
+	gin.SetMode(gin.TestMode)
+
+	serve := func(claims *Claims, permission Permission, path string) int {
+		r := gin.New()
+		r.GET("/restaurants/:id", func(c *gin.Context) {
+			if claims != nil {
+				c.Set("claims", claims)
+			}
+		}, Require(permission), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	owner := &Claims{Role: models.RoleRestaurantOwner, RestaurantId: ownRestaurant}
+	tests := []struct {
+		name       string
+		claims     *Claims
+		permission Permission
+		path       string
+		want       int
+	}{
+		{"not logged in", nil, PermRestaurantsUpdate, "/restaurants/7", http.StatusUnauthorized},
+		{"user", &Claims{Role: models.RoleUser}, PermRestaurantsUpdate, "/restaurants/7", http.StatusForbidden},
+		{"owner at own restaurant", owner, PermRestaurantsUpdate, "/restaurants/7", http.StatusOK},
+		{"owner at another restaurant", owner, PermRestaurantsUpdate, "/restaurants/8", http.StatusForbidden},
+		{"owner with an invalid id", owner, PermRestaurantsUpdate, "/restaurants/abc", http.StatusForbidden},
+		{"owner without the permission", owner, PermRestaurantsDelete, "/restaurants/7", http.StatusForbidden},
+		{"staff can't edit the restaurant", &Claims{Role: models.RoleRestaurantStaff, RestaurantId: ownRestaurant}, PermRestaurantsUpdate, "/restaurants/7", http.StatusForbidden},
+		{"admin without mfa", &Claims{Role: models.RoleAdmin}, PermRestaurantsDelete, "/restaurants/8", http.StatusForbidden},
+		{"admin with mfa", &Claims{Role: models.RoleAdmin, MFA: true}, PermRestaurantsDelete, "/restaurants/8", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serve(tt.claims, tt.permission, tt.path); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
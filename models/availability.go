@@ -0,0 +1,183 @@
+package models
+
+import (
+	"time"
+)
+
+// Slots start every 30 minutes and a booking is assumed to take 2 hours
+const (
+	SlotInterval          = 30 * time.Minute
+	DefaultDiningDuration = 2 * time.Hour
+)
+
+type Slot struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	TablesLeft int       `json:"tablesLeft"`
+}
+
+type RestaurantAvailability struct {
+	Restaurant Restaurant `json:"restaurant"`
+	TablesLeft int        `json:"tablesLeft"`
+}
+
+// Active bookings, waitlist holds and seated walk-ins between from and to, at one restaurant or at all of them when restaurantID is 0
+func (h *TableHandler) takenSlots(restaurantID uint, from time.Time, to time.Time) ([]Reservation, error) {
+	reservations := h.db.Where("date_time < ? AND exit_time > ? AND status IN ?", to, from, ActiveStatuses)
+	holds := activeHolds(h.db).Where("date_time < ? AND exit_time > ?", to, from)
+	walkIns := seatedWalkIns(h.db).Where("seated_at < ? AND leave_at > ?", to, from)
+	if restaurantID != 0 {
+		reservations = reservations.Where("restaurant_id = ?", restaurantID)
+		holds = holds.Where("restaurant_id = ?", restaurantID)
+		walkIns = walkIns.Where("restaurant_id = ?", restaurantID)
+	}
+
+	var taken []Reservation
+	if err := reservations.Find(&taken).Error; err != nil {
+		return nil, err
+	}
+
+	var held []WaitlistEntry
+	if err := holds.Find(&held).Error; err != nil {
+		return nil, err
+	}
+
+	for _, hold := range held {
+		taken = append(taken, *hold.heldSlot())
+	}
+
+	var seated []WalkIn
+	if err := walkIns.Find(&seated).Error; err != nil {
+		return nil, err
+	}
+
+	for _, walkIn := range seated {
+		taken = append(taken, *walkIn.occupiedSlot())
+	}
+	return taken, nil
+}
+
+// Count the tables that are big enough for the party and have no booking between from and to
+func countFreeTables(tables []Table, reservations []Reservation, from time.Time, to time.Time, partySize int) int {
+	free := 0
+	for _, table := range tables {
+		if table.Seats < partySize {
+			continue
+		}
+
+		taken := false
+		for _, reservation := range reservations {
+			if reservation.TableID == table.ID && reservation.DateTime.Before(to) && reservation.ExitTime.After(from) {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			free++
+		}
+	}
+	return free
+}
+
+// Bookable slots for the restaurant on date in its time zone, a slot is bookable when at least one table
+// that seats the party is free for the whole dining duration. Slots in the past or outside what the
+// booking policy allows are skipped.
+func (h *TableHandler) GetAvailability(restaurant *Restaurant, date time.Time, partySize int) ([]Slot, error) {
+	slots := []Slot{}
+
+	intervals := restaurant.IntervalsOn(date)
+	if len(intervals) == 0 {
+		return slots, nil
+	}
+	open, close := intervals[0].Open, intervals[len(intervals)-1].Close
+
+	var tables []Table
+	if err := h.db.Where("restaurant_id = ? AND seats >= ?", restaurant.ID, partySize).Find(&tables).Error; err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return slots, nil
+	}
+
+	policy, err := effectivePolicy(h.db, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := h.takenSlots(restaurant.ID, open, close)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, interval := range intervals {
+		for start := interval.Open; !start.Add(DefaultDiningDuration).After(interval.Close); start = start.Add(SlotInterval) {
+			if start.Before(now) || !policy.allowsSlot(start, partySize) {
+				continue
+			}
+
+			end := start.Add(DefaultDiningDuration)
+			if left := countFreeTables(tables, reservations, start, end, partySize); left > 0 {
+				slots = append(slots, Slot{Start: start, End: end, TablesLeft: left})
+			}
+		}
+	}
+
+	return slots, nil
+}
+
+// Restaurants that are open, have a table for the party for the whole of from..to and whose
+// booking policy allows the booking. Nothing is bookable when from is in the past.
+func (h *TableHandler) SearchAvailability(from time.Time, to time.Time, partySize int) ([]RestaurantAvailability, error) {
+	results := []RestaurantAvailability{}
+	if from.Before(time.Now()) {
+		return results, nil
+	}
+
+	var tables []Table
+	if err := h.db.Where("seats >= ?", partySize).Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	reservations, err := h.takenSlots(0, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	tablesByRestaurant := map[uint][]Table{}
+	for _, table := range tables {
+		tablesByRestaurant[table.RestaurantID] = append(tablesByRestaurant[table.RestaurantID], table)
+	}
+
+	if len(tablesByRestaurant) == 0 {
+		return results, nil
+	}
+
+	restaurantIDs := make([]uint, 0, len(tablesByRestaurant))
+	for id := range tablesByRestaurant {
+		restaurantIDs = append(restaurantIDs, id)
+	}
+
+	var restaurants []Restaurant
+	if err := withOpeningHours(h.db).Where("id IN ?", restaurantIDs).Order("id").Find(&restaurants).Error; err != nil {
+		return nil, err
+	}
+
+	policies, err := effectivePolicies(h.db, restaurantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, restaurant := range restaurants {
+		if !restaurant.IsOpenDuring(from, to) || !policies[restaurant.ID].allowsSlot(from, partySize) {
+			continue
+		}
+
+		if left := countFreeTables(tablesByRestaurant[restaurant.ID], reservations, from, to, partySize); left > 0 {
+			restaurant.setOpenNow()
+			results = append(results, RestaurantAvailability{Restaurant: restaurant, TablesLeft: left})
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTableNotFound = errors.New("table does not exist at this restaurant")
+	ErrNoTables      = errors.New("restaurant has no tables to book")
+
+	ErrReservationNotActive = errors.New("reservation is no longer active")
+)
+
+// Returned when the slot overlaps an existing booking, holds the slot that is in the way
+type ReservationConflictError struct {
+	TableNum int       `json:"tableNum"`
+	DateTime time.Time `json:"dateTime"`
+	ExitTime time.Time `json:"exitTime"`
+}
+
+func (e *ReservationConflictError) Error() string {
+	return fmt.Sprintf("table %d is already booked from %s to %s", e.TableNum, e.DateTime.Format(time.RFC3339), e.ExitTime.Format(time.RFC3339))
+}
+
+// Check the party and that the slot is the right way round, not in the past, inside the
+// opening hours and allowed by the booking policy. Returns a *ValidationError with the fields that are wrong.
+func validateSlot(tx *gorm.DB, reservation *Reservation, checkPast bool) error {
+	problems := &ValidationError{}
+	validateParty(reservation, problems)
+
+	if reservation.DateTime.IsZero() {
+		problems.Add("dateTime", "is required")
+	} else if checkPast && reservation.DateTime.Before(time.Now()) {
+		problems.Add("dateTime", "must be in the future")
+	}
+	if reservation.ExitTime.IsZero() {
+		problems.Add("exitTime", "is required")
+	} else if !reservation.ExitTime.After(reservation.DateTime) {
+		problems.Add("exitTime", "must be after dateTime")
+	}
+	if err := problems.OrNil(); err != nil {
+		return err
+	}
+
+	var restaurant Restaurant
+	if err := withOpeningHours(tx).First(&restaurant, reservation.RestaurantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			problems.Add("restaurantId", "does not exist")
+			return problems
+		}
+		return err
+	}
+
+	// Restaurants from before opening hours were validated may have none we can read
+	if restaurant.HasOpeningHours() && !restaurant.IsOpenDuring(reservation.DateTime, reservation.ExitTime) {
+		problems.Add("dateTime", "must be within the opening hours")
+		problems.Add("exitTime", "must be within the opening hours")
+	}
+
+	policy, err := effectivePolicy(tx, restaurant.ID)
+	if err != nil {
+		return err
+	}
+	policy.checkSlot(reservation, checkPast, problems)
+
+	return problems.OrNil()
+}
+
+// Lock the tables of the restaurant for the rest of the transaction,
+// so two bookings for the same restaurant can't pick the same table at once
+func lockTables(tx *gorm.DB, restaurantID uint) ([]Table, error) {
+	var tables []Table
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("restaurant_id = ?", restaurantID).
+		Order("seats, number").
+		Find(&tables)
+	return tables, result.Error
+}
+
+// First active booking, waitlist hold or seated walk-in on the table that overlaps the slot,
+// excludeID lets a reservation ignore itself
+func findOverlap(tx *gorm.DB, tableID uint, from time.Time, to time.Time, excludeID uint) (*Reservation, error) {
+	var overlaps []Reservation
+	result := tx.Where("table_id = ? AND date_time < ? AND exit_time > ? AND id <> ? AND status IN ?", tableID, to, from, excludeID, ActiveStatuses).
+		Order("exit_time").
+		Limit(1).
+		Find(&overlaps)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(overlaps) > 0 {
+		return &overlaps[0], nil
+	}
+
+	var holds []WaitlistEntry
+	result = activeHolds(tx).
+		Where("table_id = ? AND date_time < ? AND exit_time > ?", tableID, to, from).
+		Order("exit_time").
+		Limit(1).
+		Find(&holds)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(holds) > 0 {
+		return holds[0].heldSlot(), nil
+	}
+
+	var walkIns []WalkIn
+	result = seatedWalkIns(tx).
+		Where("table_id = ? AND seated_at < ? AND leave_at > ?", tableID, to, from).
+		Order("leave_at").
+		Limit(1).
+		Find(&walkIns)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(walkIns) > 0 {
+		return walkIns[0].occupiedSlot(), nil
+	}
+
+	return nil, nil
+}
+
+// Put the reservation on the requested table if it's free, or on the smallest free table that
+// seats the party when no table was requested. Must run inside a transaction.
+func assignTable(tx *gorm.DB, reservation *Reservation, partySize int, excludeID uint) error {
+	tables, err := lockTables(tx, reservation.RestaurantID)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return ErrNoTables
+	}
+
+	var candidates []Table
+	if reservation.TableNum != 0 {
+		for _, table := range tables {
+			if table.Number == reservation.TableNum {
+				candidates = []Table{table}
+				break
+			}
+		}
+		if candidates == nil {
+			return ErrTableNotFound
+		}
+		if candidates[0].Seats < partySize {
+			return &ValidationError{Fields: map[string]string{
+				"tableNum": fmt.Sprintf("only seats %d", candidates[0].Seats),
+			}}
+		}
+	} else {
+		for _, table := range tables {
+			if table.Seats >= partySize {
+				candidates = append(candidates, table)
+			}
+		}
+		if candidates == nil {
+			// Tables are sorted by seats, so the last one is the largest
+			return &ValidationError{Fields: map[string]string{
+				"partySize": fmt.Sprintf("is more than the largest table seats (%d)", tables[len(tables)-1].Seats),
+			}}
+		}
+	}
+
+	// Remember the conflict that frees up first, that's the most useful one to report
+	var conflict *ReservationConflictError
+	for _, table := range candidates {
+		overlap, err := findOverlap(tx, table.ID, reservation.DateTime, reservation.ExitTime, excludeID)
+		if err != nil {
+			return err
+		}
+
+		if overlap == nil {
+			reservation.TableID = table.ID
+			reservation.TableNum = table.Number
+			return nil
+		}
+
+		if conflict == nil || overlap.ExitTime.Before(conflict.ExitTime) {
+			conflict = &ReservationConflictError{
+				TableNum: table.Number,
+				DateTime: overlap.DateTime,
+				ExitTime: overlap.ExitTime,
+			}
+		}
+	}
+
+	return conflict
+}
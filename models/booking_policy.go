@@ -0,0 +1,370 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Booking rules for one restaurant, or the global defaults when RestaurantID is nil.
+// A nil field falls back to the global policy and then to DefaultBookingPolicy, 0 means no limit.
+// MaxActiveBookings of a restaurant is an extra limit for bookings there, on top of the global one.
+type BookingPolicy struct {
+	ID                uint  `gorm:"primaryKey"`
+	RestaurantID      *uint `json:"restaurantId" gorm:"uniqueIndex"`
+	MaxActiveBookings *int  `json:"maxActiveBookings" example:"3"`
+	MinLeadMinutes    *int  `json:"minLeadMinutes" example:"60"`
+	MaxDaysInAdvance  *int  `json:"maxDaysInAdvance" example:"30"`
+	MaxPartySize      *int  `json:"maxPartySize" example:"10"`
+	CancellationHours *int  `json:"cancellationHours" example:"24"`
+	NoShowLimit       *int  `json:"noShowLimit" example:"2"`
+	NoShowPeriodDays  *int  `json:"noShowPeriodDays" example:"90"`
+	gorm.Model        `json:"-" swaggerignore:"true"`
+}
+
+// The rules that apply after falling back, 0 means no limit
+type EffectivePolicy struct {
+	MaxActiveBookings int `json:"maxActiveBookings" example:"3"`
+	MinLeadMinutes    int `json:"minLeadMinutes" example:"60"`
+	MaxDaysInAdvance  int `json:"maxDaysInAdvance" example:"30"`
+	MaxPartySize      int `json:"maxPartySize" example:"10"`
+	CancellationHours int `json:"cancellationHours" example:"24"`
+	NoShowLimit       int `json:"noShowLimit" example:"2"`
+	NoShowPeriodDays  int `json:"noShowPeriodDays" example:"90"`
+}
+
+// Used when there is no global policy yet, it keeps the old limit of 3 active bookings
+var DefaultBookingPolicy = EffectivePolicy{
+	MaxActiveBookings: 3,
+	MinLeadMinutes:    0,
+	MaxDaysInAdvance:  90,
+	MaxPartySize:      20,
+	CancellationHours: 0,
+	NoShowLimit:       0,
+	NoShowPeriodDays:  90,
+}
+
+// Returned when the user already has as many active bookings as the policy allows,
+// AtRestaurant when it's the restaurant's own limit
+type BookingLimitError struct {
+	Limit        int
+	AtRestaurant bool
+}
+
+func (e *BookingLimitError) Error() string {
+	if e.AtRestaurant {
+		return fmt.Sprintf("User already has %d active reservations at this restaurant. Cannot create more.", e.Limit)
+	}
+	return fmt.Sprintf("User already has %d active reservations. Cannot create more.", e.Limit)
+}
+
+func (p EffectivePolicy) apply(policy *BookingPolicy) EffectivePolicy {
+	if policy == nil {
+		return p
+	}
+	if policy.MaxActiveBookings != nil {
+		p.MaxActiveBookings = *policy.MaxActiveBookings
+	}
+	if policy.MinLeadMinutes != nil {
+		p.MinLeadMinutes = *policy.MinLeadMinutes
+	}
+	if policy.MaxDaysInAdvance != nil {
+		p.MaxDaysInAdvance = *policy.MaxDaysInAdvance
+	}
+	if policy.MaxPartySize != nil {
+		p.MaxPartySize = *policy.MaxPartySize
+	}
+	if policy.CancellationHours != nil {
+		p.CancellationHours = *policy.CancellationHours
+	}
+	if policy.NoShowLimit != nil {
+		p.NoShowLimit = *policy.NoShowLimit
+	}
+	if policy.NoShowPeriodDays != nil {
+		p.NoShowPeriodDays = *policy.NoShowPeriodDays
+	}
+	return p
+}
+
+// Check the limits of a policy before saving it, all of them can't be negative
+func (p *BookingPolicy) Validate() error {
+	problems := &ValidationError{}
+	for field, value := range map[string]*int{
+		"maxActiveBookings": p.MaxActiveBookings,
+		"minLeadMinutes":    p.MinLeadMinutes,
+		"maxDaysInAdvance":  p.MaxDaysInAdvance,
+		"maxPartySize":      p.MaxPartySize,
+		"cancellationHours": p.CancellationHours,
+		"noShowLimit":       p.NoShowLimit,
+		"noShowPeriodDays":  p.NoShowPeriodDays,
+	} {
+		if value != nil && *value < 0 {
+			problems.Add(field, "can't be negative, use 0 for no limit")
+		}
+	}
+	return problems.OrNil()
+}
+
+func findPolicy(db *gorm.DB, restaurantID *uint) (*BookingPolicy, error) {
+	var policies []BookingPolicy
+	query := db.Limit(1)
+	if restaurantID == nil {
+		query = query.Where("restaurant_id IS NULL")
+	} else {
+		query = query.Where("restaurant_id = ?", *restaurantID)
+	}
+	if err := query.Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return &policies[0], nil
+}
+
+// The rules for booking at the restaurant: its own policy, then the global one, then the defaults
+func effectivePolicy(db *gorm.DB, restaurantID uint) (EffectivePolicy, error) {
+	global, err := findPolicy(db, nil)
+	if err != nil {
+		return EffectivePolicy{}, err
+	}
+	own, err := findPolicy(db, &restaurantID)
+	if err != nil {
+		return EffectivePolicy{}, err
+	}
+	return DefaultBookingPolicy.apply(global).apply(own), nil
+}
+
+// The rules for booking at each of the restaurants, like effectivePolicy but in two queries
+func effectivePolicies(db *gorm.DB, restaurantIDs []uint) (map[uint]EffectivePolicy, error) {
+	global, err := findPolicy(db, nil)
+	if err != nil {
+		return nil, err
+	}
+	var own []BookingPolicy
+	if err := db.Where("restaurant_id IN ?", restaurantIDs).Find(&own).Error; err != nil {
+		return nil, err
+	}
+
+	base := DefaultBookingPolicy.apply(global)
+	policies := make(map[uint]EffectivePolicy, len(restaurantIDs))
+	for _, id := range restaurantIDs {
+		policies[id] = base
+	}
+	for i := range own {
+		policies[*own[i].RestaurantID] = base.apply(&own[i])
+	}
+	return policies, nil
+}
+
+// Whether a new booking for the party starting at start passes the policy, so availability
+// doesn't offer slots that booking them would refuse
+func (p EffectivePolicy) allowsSlot(start time.Time, partySize int) bool {
+	problems := &ValidationError{}
+	p.checkSlot(&Reservation{DateTime: start, PartySize: partySize}, true, problems)
+	return problems.OrNil() == nil
+}
+
+// Check the lead time, how far ahead and the party size of a slot. The time limits are
+// only checked when checkTimes is set, so editing a booking doesn't fail on them later.
+func (p EffectivePolicy) checkSlot(reservation *Reservation, checkTimes bool, problems *ValidationError) {
+	if p.MaxPartySize > 0 && reservation.PartySize > p.MaxPartySize {
+		problems.Add("partySize", fmt.Sprintf("can be at most %d guests at this restaurant", p.MaxPartySize))
+	}
+	if !checkTimes {
+		return
+	}
+
+	now := time.Now()
+	if p.MinLeadMinutes > 0 && reservation.DateTime.Before(now.Add(time.Duration(p.MinLeadMinutes)*time.Minute)) {
+		problems.Add("dateTime", fmt.Sprintf("must be at least %d minutes from now", p.MinLeadMinutes))
+	}
+	if p.MaxDaysInAdvance > 0 && reservation.DateTime.After(now.AddDate(0, 0, p.MaxDaysInAdvance)) {
+		problems.Add("dateTime", fmt.Sprintf("can be at most %d days from now", p.MaxDaysInAdvance))
+	}
+}
+
+// Returned when the guest didn't show up too often lately and can't book until Until
+type BookingRestrictedError struct {
+	NoShows int
+	Until   time.Time
+}
+
+func (e *BookingRestrictedError) Error() string {
+	return fmt.Sprintf("Booking is restricted after %d no-shows, try again after %s", e.NoShows, e.Until.Format(time.RFC3339))
+}
+
+// Returned when a guest tries to cancel later than the restaurant allows
+type CancellationWindowError struct {
+	Hours    int
+	Deadline time.Time
+}
+
+func (e *CancellationWindowError) Error() string {
+	return fmt.Sprintf("Reservations can only be cancelled up to %d hours before, the deadline was %s", e.Hours, e.Deadline.Format(time.RFC3339))
+}
+
+// Guests can cancel until CancellationHours before the booking starts
+func checkCancellationWindow(tx *gorm.DB, reservation *Reservation) error {
+	policy, err := effectivePolicy(tx, reservation.RestaurantID)
+	if err != nil {
+		return err
+	}
+	if policy.CancellationHours == 0 {
+		return nil
+	}
+
+	deadline := reservation.DateTime.Add(-time.Duration(policy.CancellationHours) * time.Hour)
+	if time.Now().After(deadline) {
+		return &CancellationWindowError{Hours: policy.CancellationHours, Deadline: deadline}
+	}
+	return nil
+}
+
+// No-shows of the user at any restaurant within the rolling period count towards the restriction.
+// The restriction ends once enough of them fall out of the period.
+func checkNoShows(tx *gorm.DB, userID uint, policy EffectivePolicy) error {
+	if policy.NoShowLimit == 0 || policy.NoShowPeriodDays == 0 {
+		return nil
+	}
+
+	period := time.Duration(policy.NoShowPeriodDays) * 24 * time.Hour
+	var noShows []time.Time
+	result := tx.Model(&Reservation{}).
+		Where("user_id = ? AND status = ? AND date_time > ?", userID, StatusNoShow, time.Now().Add(-period)).
+		Order("date_time").
+		Pluck("date_time", &noShows)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if len(noShows) < policy.NoShowLimit {
+		return nil
+	}
+	return &BookingRestrictedError{
+		NoShows: len(noShows),
+		Until:   noShows[len(noShows)-policy.NoShowLimit].Add(period),
+	}
+}
+
+// Upcoming bookings of the user that still hold a table, at every restaurant when restaurantID is nil.
+// Bookings that already ended but were never closed by the staff don't count anymore, and all
+// occurrences of a recurring series together count as one booking.
+func activeBookings(tx *gorm.DB, userID uint, restaurantID *uint) (int64, error) {
+	query := tx.Model(&Reservation{}).Where("user_id = ? AND status IN ? AND exit_time > ?", userID, ActiveStatuses, time.Now())
+	if restaurantID != nil {
+		query = query.Where("restaurant_id = ?", *restaurantID)
+	}
+
+	var single, series int64
+	if err := query.Session(&gorm.Session{}).Where("series_id IS NULL").Count(&single).Error; err != nil {
+		return 0, err
+	}
+	if err := query.Session(&gorm.Session{}).Where("series_id IS NOT NULL").Distinct("series_id").Count(&series).Error; err != nil {
+		return 0, err
+	}
+	return single + series, nil
+}
+
+// Make sure the user isn't restricted for no-shows and has room for one more active booking.
+// The global maxActiveBookings counts bookings at every restaurant, a restaurant's own one
+// only those at the restaurant, so a restaurant can be stricter but never lift the global limit.
+// The user row stays locked until the transaction ends, so two bookings at once can't both
+// see the old count. Must run inside a transaction.
+func enforceBookingLimit(tx *gorm.DB, userID uint, restaurantID uint) error {
+	global, err := findPolicy(tx, nil)
+	if err != nil {
+		return err
+	}
+	own, err := findPolicy(tx, &restaurantID)
+	if err != nil {
+		return err
+	}
+	policy := DefaultBookingPolicy.apply(global).apply(own)
+
+	var user User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	if err := checkNoShows(tx, userID, policy); err != nil {
+		return err
+	}
+
+	if limit := DefaultBookingPolicy.apply(global).MaxActiveBookings; limit > 0 {
+		active, err := activeBookings(tx, userID, nil)
+		if err != nil {
+			return err
+		}
+		if active >= int64(limit) {
+			return &BookingLimitError{Limit: limit}
+		}
+	}
+
+	if own != nil && own.MaxActiveBookings != nil && *own.MaxActiveBookings > 0 {
+		active, err := activeBookings(tx, userID, &restaurantID)
+		if err != nil {
+			return err
+		}
+		if active >= int64(*own.MaxActiveBookings) {
+			return &BookingLimitError{Limit: *own.MaxActiveBookings, AtRestaurant: true}
+		}
+	}
+	return nil
+}
+
+type BookingPolicyHandler struct {
+	db *gorm.DB
+}
+
+func NewBookingPolicyHandler(db *gorm.DB) *BookingPolicyHandler {
+	return &BookingPolicyHandler{db}
+}
+
+// The stored policy, or an empty one when there is none, so every field falls back
+func (h *BookingPolicyHandler) GetPolicy(restaurantID *uint) (*BookingPolicy, error) {
+	policy, err := findPolicy(h.db, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = &BookingPolicy{RestaurantID: restaurantID}
+	}
+	return policy, nil
+}
+
+func (h *BookingPolicyHandler) GetEffectivePolicy(restaurantID uint) (EffectivePolicy, error) {
+	return effectivePolicy(h.db, restaurantID)
+}
+
+func (h *BookingPolicyHandler) GetGlobalPolicy() (EffectivePolicy, error) {
+	global, err := findPolicy(h.db, nil)
+	if err != nil {
+		return EffectivePolicy{}, err
+	}
+	return DefaultBookingPolicy.apply(global), nil
+}
+
+// Replace the policy of the restaurant, or the global one when restaurantID is nil
+func (h *BookingPolicyHandler) SavePolicy(restaurantID *uint, policy *BookingPolicy) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		current, err := findPolicy(tx, restaurantID)
+		if err != nil {
+			return err
+		}
+
+		policy.RestaurantID = restaurantID
+		if current == nil {
+			policy.ID = 0
+			return tx.Create(policy).Error
+		}
+
+		policy.ID = current.ID
+		policy.Model = current.Model
+		// Select all so fields that were cleared back to nil are saved too
+		return tx.Model(current).Select("max_active_bookings", "min_lead_minutes", "max_days_in_advance", "max_party_size",
+			"cancellation_hours", "no_show_limit", "no_show_period_days").Updates(policy).Error
+	})
+}
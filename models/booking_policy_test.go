@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffectivePolicyAllowsSlot(t *testing.T) {
+	policy := EffectivePolicy{MinLeadMinutes: 60, MaxDaysInAdvance: 30, MaxPartySize: 8}
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		policy    EffectivePolicy
+		start     time.Time
+		partySize int
+		want      bool
+	}{
+		{"within the limits", policy, now.Add(2 * time.Hour), 4, true},
+		{"largest party", policy, now.Add(2 * time.Hour), 8, true},
+		{"party too big", policy, now.Add(2 * time.Hour), 9, false},
+		{"too soon", policy, now.Add(30 * time.Minute), 2, false},
+		{"too far ahead", policy, now.AddDate(0, 0, 31), 2, false},
+		{"last day", policy, now.AddDate(0, 0, 29), 2, true},
+		{"no limits", EffectivePolicy{}, now.Add(time.Minute), 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.allowsSlot(tt.start, tt.partySize); got != tt.want {
+				t.Errorf("allowsSlot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectivePolicyApply(t *testing.T) {
+	zero, five := 0, 5
+	got := DefaultBookingPolicy.
+		apply(&BookingPolicy{MaxActiveBookings: &five, MaxPartySize: &five}).
+		apply(&BookingPolicy{MaxPartySize: &zero})
+
+	if got.MaxActiveBookings != 5 {
+		t.Errorf("MaxActiveBookings = %d, want the global 5", got.MaxActiveBookings)
+	}
+	if got.MaxPartySize != 0 {
+		t.Errorf("MaxPartySize = %d, want the restaurant's 0", got.MaxPartySize)
+	}
+	if got.MaxDaysInAdvance != DefaultBookingPolicy.MaxDaysInAdvance {
+		t.Errorf("MaxDaysInAdvance = %d, want the default", got.MaxDaysInAdvance)
+	}
+	if got := DefaultBookingPolicy.apply(nil); got != DefaultBookingPolicy {
+		t.Errorf("apply(nil) = %+v, want the defaults", got)
+	}
+}
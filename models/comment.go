@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 	"gorm.io/gorm"
 )
@@ -17,6 +18,15 @@ type Comment struct {
 	gorm.Model   `json:"-" swaggerignore:"true"`
 }
 
+// Nested users only show their public profile
+func (c Comment) MarshalJSON() ([]byte, error) {
+	type comment Comment
+	return json.Marshal(struct {
+		comment
+		User PublicUser `json:"user"`
+	}{comment(c), c.User.PublicView()})
+}
+
 type CommentHandler struct {
 	db *gorm.DB
 }
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+)
+
+// Filters for a restaurant's reservation list, zero values don't filter
+type ReservationFilter struct {
+	From     time.Time
+	To       time.Time
+	Statuses []ReservationStatus
+	TableNum int
+}
+
+type HourCovers struct {
+	Hour         time.Time `json:"hour"`
+	Covers       int       `json:"covers" example:"14"`
+	Reservations int       `json:"reservations" example:"5"`
+}
+
+type TableOccupancy struct {
+	TableID       uint    `json:"tableId"`
+	TableNum      int     `json:"tableNum" example:"4"`
+	Seats         int     `json:"seats" example:"4"`
+	Reservations  int     `json:"reservations" example:"3"`
+	Covers        int     `json:"covers" example:"9"`
+	BookedMinutes int     `json:"bookedMinutes" example:"360"`
+	Occupancy     float64 `json:"occupancy" example:"0.6"`
+}
+
+// Everything the staff need to see about one day at the restaurant
+type DayView struct {
+	Date         string                    `json:"date" example:"2024-05-01"`
+	TimeZone     string                    `json:"timeZone" example:"Asia/Bangkok"`
+	OpenMinutes  int                       `json:"openMinutes" example:"600"`
+	Covers       int                       `json:"covers" example:"42"`
+	Reservations int                       `json:"reservations" example:"15"`
+	ByStatus     map[ReservationStatus]int `json:"byStatus"`
+	Hours        []HourCovers              `json:"hours"`
+	Tables       []TableOccupancy          `json:"tables"`
+	Bookings     []Reservation             `json:"bookings"`
+}
+
+// Cancelled bookings and no-shows never sat at a table, so they don't count towards covers
+func (s ReservationStatus) takesTable() bool {
+	return s.IsActive() || s == StatusCompleted
+}
+
+// Minutes of the reservation that fall between from and to
+func overlapMinutes(reservation *Reservation, from time.Time, to time.Time) int {
+	start, end := reservation.DateTime, reservation.ExitTime
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return int(end.Sub(start) / time.Minute)
+}
+
+// Covers per hour and how busy every table is on date in the restaurant's time zone, from
+// opening to closing. Days without opening hours are looked at from midnight to midnight.
+func (h *ReservationHandler) GetDayView(restaurant *Restaurant, date time.Time) (*DayView, error) {
+	loc := restaurant.Location()
+	local := date.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	from, to := midnight, midnight.AddDate(0, 0, 1)
+	openMinutes := int(to.Sub(from) / time.Minute)
+	if intervals := restaurant.IntervalsOn(midnight); len(intervals) > 0 {
+		from, to = intervals[0].Open, intervals[len(intervals)-1].Close
+		openMinutes = 0
+		for _, interval := range intervals {
+			openMinutes += int(interval.Close.Sub(interval.Open) / time.Minute)
+		}
+	}
+
+	var bookings []Reservation
+	result := h.db.Preload("User").
+		Where("restaurant_id = ? AND date_time < ? AND exit_time > ?", restaurant.ID, to, from).
+		Order("date_time, table_num").
+		Find(&bookings)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var tables []Table
+	if err := h.db.Where("restaurant_id = ?", restaurant.ID).Order("number").Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	view := &DayView{
+		Date:        midnight.Format("2006-01-02"),
+		TimeZone:    loc.String(),
+		OpenMinutes: openMinutes,
+		ByStatus:    map[ReservationStatus]int{},
+		Hours:       []HourCovers{},
+		Tables:      []TableOccupancy{},
+		Bookings:    bookings,
+	}
+	if view.Bookings == nil {
+		view.Bookings = []Reservation{}
+	}
+
+	for _, booking := range bookings {
+		view.ByStatus[booking.Status]++
+		if booking.Status.takesTable() {
+			view.Reservations++
+			view.Covers += booking.PartySize
+		}
+	}
+
+	// Hours start on the hour, so a 17:30 opening shows up in the 17:00 row
+	start := from.In(loc)
+	start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, loc)
+	for hour := start; hour.Before(to); hour = hour.Add(time.Hour) {
+		row := HourCovers{Hour: hour}
+		for i := range bookings {
+			if bookings[i].Status.takesTable() && overlapMinutes(&bookings[i], hour, hour.Add(time.Hour)) > 0 {
+				row.Covers += bookings[i].PartySize
+				row.Reservations++
+			}
+		}
+		view.Hours = append(view.Hours, row)
+	}
+
+	for _, table := range tables {
+		row := TableOccupancy{TableID: table.ID, TableNum: table.Number, Seats: table.Seats}
+		for i := range bookings {
+			if bookings[i].TableID != table.ID || !bookings[i].Status.takesTable() {
+				continue
+			}
+			row.Reservations++
+			row.Covers += bookings[i].PartySize
+			row.BookedMinutes += overlapMinutes(&bookings[i], from, to)
+		}
+		if openMinutes > 0 {
+			row.Occupancy = float64(row.BookedMinutes) / float64(openMinutes)
+			if row.Occupancy > 1 {
+				row.Occupancy = 1
+			}
+		}
+		view.Tables = append(view.Tables, row)
+	}
+
+	return view, nil
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginAttempt struct {
+	ID         uint      `gorm:"primaryKey"`
+	Email      string    `json:"email" gorm:"index"`
+	IP         string    `json:"ip" gorm:"index"`
+	Success    bool      `json:"success"`
+	AttemptAt  time.Time `json:"attemptAt" gorm:"index"`
+	gorm.Model `json:"-" swaggerignore:"true"`
+}
+
+type LoginAttemptHandler struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptHandler(db *gorm.DB) *LoginAttemptHandler {
+	return &LoginAttemptHandler{db}
+}
+
+func (h *LoginAttemptHandler) RecordAttempt(email string, ip string, success bool) error {
+	return h.db.Create(&LoginAttempt{
+		Email:     email,
+		IP:        ip,
+		Success:   success,
+		AttemptAt: time.Now(),
+	}).Error
+}
+
+// Failed attempts newer than since, newest first
+func (h *LoginAttemptHandler) GetFailedAttempts(since time.Time) ([]LoginAttempt, error) {
+	var attempts []LoginAttempt
+	result := h.db.Where("success = ? AND attempt_at > ?", false, since).Order("attempt_at desc").Find(&attempts)
+	return attempts, result.Error
+}
+
+// Remove the failed attempts of an account, from any ip, and those of an ip, for any account.
+// Either can be empty. Matches how the account and ip limiters are reset on their own.
+func (h *LoginAttemptHandler) ClearFailedAttempts(email string, ip string) error {
+	if email == "" && ip == "" {
+		return nil
+	}
+
+	query := h.db.Where("success = ?", false)
+	switch {
+	case email != "" && ip != "":
+		query = query.Where("email = ? OR ip = ?", email, ip)
+	case email != "":
+		query = query.Where("email = ?", email)
+	default:
+		query = query.Where("ip = ?", ip)
+	}
+	return query.Delete(&LoginAttempt{}).Error
+}
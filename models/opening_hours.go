@@ -0,0 +1,327 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// One opening on a weekday, e.g. lunch 11:00-14:00 on Mondays. Weekday 0 is Sunday.
+// A close time at or before the open time means it closes after midnight.
+type OpeningInterval struct {
+	ID           uint   `gorm:"primaryKey"`
+	RestaurantID uint   `json:"restaurantId" gorm:"index"`
+	Weekday      int    `json:"weekday" example:"1"`
+	OpenTime     string `json:"openTime" example:"11:00"`
+	CloseTime    string `json:"closeTime" example:"14:00"`
+	gorm.Model   `json:"-" swaggerignore:"true"`
+}
+
+// Different hours on one date, like a public holiday. Closed exceptions close the whole day,
+// otherwise the exceptions of a date replace the weekly schedule for that day.
+type OpeningException struct {
+	ID           uint   `gorm:"primaryKey"`
+	RestaurantID uint   `json:"restaurantId" gorm:"index"`
+	Date         string `json:"date" gorm:"index" example:"2024-12-31"`
+	Closed       bool   `json:"closed"`
+	OpenTime     string `json:"openTime" example:"18:00"`
+	CloseTime    string `json:"closeTime" example:"02:00"`
+	Note         string `json:"note" example:"New Year's Eve"`
+	gorm.Model   `json:"-" swaggerignore:"true"`
+}
+
+// A concrete opening on a day
+type Interval struct {
+	Open  time.Time
+	Close time.Time
+}
+
+var ErrExceptionNotFound = errors.New("opening exception not found")
+
+var clockLayouts = []string{"15:04", "15:04:05", "3:04PM", "3:04 PM", "3PM", "3 PM"}
+
+// Parse an opening time like "18:00" or "6:30 PM" into the time after midnight
+func parseClock(value string) (time.Duration, bool) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+		}
+	}
+	return 0, false
+}
+
+func formatClock(d time.Duration) string {
+	return time.Time{}.Add(d).Format("15:04")
+}
+
+// Check the opening hours of a restaurant and return them as HH:MM. A close time at or
+// before the open time is fine, it means the restaurant closes after midnight.
+func NormalizeOpeningHours(openTime string, closeTime string) (string, string, error) {
+	return normalizeHours(openTime, closeTime, "openTime", "closeTime")
+}
+
+func normalizeHours(openTime string, closeTime string, openField string, closeField string) (string, string, error) {
+	problems := &ValidationError{}
+
+	open, ok := parseClock(openTime)
+	if !ok {
+		problems.Add(openField, "must be a time of day like 18:00")
+	}
+	close, ok := parseClock(closeTime)
+	if !ok {
+		problems.Add(closeField, "must be a time of day like 02:00")
+	}
+	if err := problems.OrNil(); err != nil {
+		return "", "", err
+	}
+
+	return formatClock(open), formatClock(close), nil
+}
+
+func ValidateTimeZone(name string) error {
+	if _, err := time.LoadLocation(name); err != nil {
+		return &ValidationError{Fields: map[string]string{"timeZone": "must be an IANA time zone like Asia/Bangkok"}}
+	}
+	return nil
+}
+
+// The restaurant's time zone, restaurants without one use the server's
+func (r *Restaurant) Location() *time.Location {
+	if r.TimeZone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// Turn an open and close time into an interval on day, closing after midnight when needed
+func intervalOn(day time.Time, openTime string, closeTime string) (Interval, bool) {
+	open, ok := parseClock(openTime)
+	if !ok {
+		return Interval{}, false
+	}
+	close, ok := parseClock(closeTime)
+	if !ok {
+		return Interval{}, false
+	}
+	closeDay := day.Day()
+	if close <= open {
+		closeDay++
+	}
+
+	// Built from the wall clock, so 18:00 stays 18:00 on the days the clocks change
+	at := func(d int, clock time.Duration) time.Time {
+		return time.Date(day.Year(), day.Month(), d, int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, day.Location())
+	}
+	return Interval{Open: at(day.Day(), open), Close: at(closeDay, close)}, true
+}
+
+// The openings that start on the day of date in the restaurant's time zone, in order.
+// Dated exceptions win over the weekly schedule, and restaurants without a schedule
+// fall back to OpenTime and CloseTime every day. Needs Schedule and Exceptions loaded.
+func (r *Restaurant) IntervalsOn(date time.Time) []Interval {
+	day := date.In(r.Location())
+	intervals := []Interval{}
+
+	dateString := day.Format("2006-01-02")
+	hasException := false
+	for _, exception := range r.Exceptions {
+		if exception.Date != dateString {
+			continue
+		}
+		if exception.Closed {
+			return []Interval{}
+		}
+		hasException = true
+		if interval, ok := intervalOn(day, exception.OpenTime, exception.CloseTime); ok {
+			intervals = append(intervals, interval)
+		}
+	}
+
+	if !hasException {
+		if len(r.Schedule) > 0 {
+			for _, opening := range r.Schedule {
+				if opening.Weekday != int(day.Weekday()) {
+					continue
+				}
+				if interval, ok := intervalOn(day, opening.OpenTime, opening.CloseTime); ok {
+					intervals = append(intervals, interval)
+				}
+			}
+		} else if interval, ok := intervalOn(day, r.OpenTime, r.CloseTime); ok {
+			intervals = append(intervals, interval)
+		}
+	}
+
+	return mergeIntervals(intervals)
+}
+
+// Sort the intervals and join the ones that touch or overlap, so 11-14 and 14-22 are one opening
+func mergeIntervals(intervals []Interval) []Interval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Open.Before(intervals[j].Open) })
+
+	merged := []Interval{}
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Open.After(merged[last].Close) {
+			if interval.Close.After(merged[last].Close) {
+				merged[last].Close = interval.Close
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+func (r *Restaurant) HasOpeningHours() bool {
+	if len(r.Schedule) > 0 || len(r.Exceptions) > 0 {
+		return true
+	}
+	_, ok := intervalOn(time.Now(), r.OpenTime, r.CloseTime)
+	return ok
+}
+
+// Is the restaurant open for the whole of from..to, also checks the previous day for overnight hours
+func (r *Restaurant) IsOpenDuring(from time.Time, to time.Time) bool {
+	local := from.In(r.Location())
+	for _, day := range []time.Time{local.AddDate(0, 0, -1), local} {
+		for _, interval := range r.IntervalsOn(day) {
+			if !from.Before(interval.Open) && !to.After(interval.Close) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Restaurant) IsOpenAt(t time.Time) bool {
+	return r.IsOpenDuring(t, t)
+}
+
+// Check a weekly schedule and return it with the times as HH:MM. Intervals must not overlap,
+// also not when one runs past midnight into the next day's.
+func NormalizeSchedule(intervals []OpeningInterval) ([]OpeningInterval, error) {
+	problems := &ValidationError{}
+	normalized := make([]OpeningInterval, 0, len(intervals))
+
+	for i, interval := range intervals {
+		field := fmt.Sprintf("intervals[%d]", i)
+		if interval.Weekday < 0 || interval.Weekday > 6 {
+			problems.Add(field+".weekday", "must be between 0 (Sunday) and 6 (Saturday)")
+		}
+
+		openTime, closeTime, err := normalizeHours(interval.OpenTime, interval.CloseTime, field+".openTime", field+".closeTime")
+		if err != nil {
+			for key, message := range err.(*ValidationError).Fields {
+				problems.Add(key, message)
+			}
+			continue
+		}
+
+		normalized = append(normalized, OpeningInterval{
+			Weekday:   interval.Weekday,
+			OpenTime:  openTime,
+			CloseTime: closeTime,
+		})
+	}
+	if err := problems.OrNil(); err != nil {
+		return nil, err
+	}
+
+	// Compare every pair on a fixed week so overnight hours line up with the next day. Saturday
+	// night runs into Sunday, so the second one is also tried a week earlier and later.
+	week := time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC) // a Sunday
+	overlaps := func(a Interval, b Interval) bool {
+		return a.Open.Before(b.Close) && b.Open.Before(a.Close)
+	}
+	for i := range normalized {
+		for j := i + 1; j < len(normalized); j++ {
+			a, b := normalized[i], normalized[j]
+			first, _ := intervalOn(week.AddDate(0, 0, a.Weekday), a.OpenTime, a.CloseTime)
+			for _, shift := range []int{-7, 0, 7} {
+				second, _ := intervalOn(week.AddDate(0, 0, b.Weekday+shift), b.OpenTime, b.CloseTime)
+				if overlaps(first, second) {
+					problems.Add(fmt.Sprintf("intervals[%d]", j), fmt.Sprintf("overlaps intervals[%d]", i))
+					break
+				}
+			}
+		}
+	}
+
+	return normalized, problems.OrNil()
+}
+
+// Check a dated exception, special hours need an open and close time
+func NormalizeException(exception *OpeningException) error {
+	problems := &ValidationError{}
+
+	date, err := time.Parse("2006-01-02", exception.Date)
+	if err != nil {
+		problems.Add("date", "must look like 2024-12-31")
+	} else {
+		exception.Date = date.Format("2006-01-02")
+	}
+
+	if exception.Closed {
+		exception.OpenTime = ""
+		exception.CloseTime = ""
+	} else {
+		openTime, closeTime, err := NormalizeOpeningHours(exception.OpenTime, exception.CloseTime)
+		if err != nil {
+			for key, message := range err.(*ValidationError).Fields {
+				problems.Add(key, message)
+			}
+		}
+		exception.OpenTime, exception.CloseTime = openTime, closeTime
+	}
+
+	return problems.OrNil()
+}
+
+// Replace the whole weekly schedule of the restaurant, and its time zone when one is given
+func (h *RestaurantHandler) ReplaceSchedule(restaurantID uint, timeZone string, intervals []OpeningInterval) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		if timeZone != "" {
+			if err := tx.Model(&Restaurant{}).Where("id = ?", restaurantID).Update("time_zone", timeZone).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Where("restaurant_id = ?", restaurantID).Delete(&OpeningInterval{}).Error; err != nil {
+			return err
+		}
+
+		for i := range intervals {
+			intervals[i].ID = 0
+			intervals[i].RestaurantID = restaurantID
+		}
+		if len(intervals) == 0 {
+			return nil
+		}
+		return tx.Create(&intervals).Error
+	})
+}
+
+func (h *RestaurantHandler) CreateException(exception *OpeningException) error {
+	return h.db.Create(exception).Error
+}
+
+func (h *RestaurantHandler) DeleteException(restaurantID uint, id uint) error {
+	result := h.db.Where("restaurant_id = ?", restaurantID).Delete(&OpeningException{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrExceptionNotFound
+	}
+	return nil
+}
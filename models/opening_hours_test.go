@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIntervalOn(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, newYork)
+	}
+
+	tests := []struct {
+		name      string
+		day       time.Time
+		openTime  string
+		closeTime string
+		open      time.Time
+		close     time.Time
+	}{
+		{"ordinary day", at(5, 1, 0, 0), "11:00", "14:00", at(5, 1, 11, 0), at(5, 1, 14, 0)},
+		{"clocks go forward", at(3, 10, 0, 0), "18:00", "23:30", at(3, 10, 18, 0), at(3, 10, 23, 30)},
+		{"clocks go back", at(11, 3, 0, 0), "18:00", "23:30", at(11, 3, 18, 0), at(11, 3, 23, 30)},
+		{"overnight into the change", at(11, 2, 0, 0), "20:00", "02:00", at(11, 2, 20, 0), at(11, 3, 2, 0)},
+		{"overnight at the end of the month", at(5, 31, 12, 0), "18:00", "01:00", at(5, 31, 18, 0), at(6, 1, 1, 0)},
+		{"open all day", at(5, 1, 0, 0), "09:00", "09:00", at(5, 1, 9, 0), at(5, 2, 9, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval, ok := intervalOn(tt.day, tt.openTime, tt.closeTime)
+			if !ok {
+				t.Fatal("intervalOn() couldn't parse the times")
+			}
+			if !interval.Open.Equal(tt.open) || !interval.Close.Equal(tt.close) {
+				t.Errorf("intervalOn() = %s - %s, want %s - %s", interval.Open, interval.Close, tt.open, tt.close)
+			}
+		})
+	}
+
+	if _, ok := intervalOn(at(5, 1, 0, 0), "noon", "14:00"); ok {
+		t.Error("intervalOn() accepted an invalid time")
+	}
+}
+
+func TestNormalizeSchedule(t *testing.T) {
+	opening := func(weekday int, openTime string, closeTime string) OpeningInterval {
+		return OpeningInterval{Weekday: weekday, OpenTime: openTime, CloseTime: closeTime}
+	}
+
+	tests := []struct {
+		name      string
+		intervals []OpeningInterval
+		invalid   []string
+	}{
+		{"lunch and dinner", []OpeningInterval{opening(1, "11:00", "14:00"), opening(1, "6 PM", "22:00")}, nil},
+		{"touching", []OpeningInterval{opening(1, "11:00", "14:00"), opening(1, "14:00", "22:00")}, nil},
+		{"same day overlap", []OpeningInterval{opening(1, "11:00", "15:00"), opening(1, "14:00", "22:00")}, []string{"intervals[1]"}},
+		{"overnight into the next morning", []OpeningInterval{opening(5, "20:00", "03:00"), opening(6, "02:00", "10:00")}, []string{"intervals[1]"}},
+		{"overnight until the next opening", []OpeningInterval{opening(5, "20:00", "03:00"), opening(6, "03:00", "10:00")}, nil},
+		{"saturday night into sunday", []OpeningInterval{opening(0, "01:00", "10:00"), opening(6, "22:00", "02:00")}, []string{"intervals[1]"}},
+		{"overnight on different days", []OpeningInterval{opening(2, "20:00", "03:00"), opening(5, "02:00", "10:00")}, nil},
+		{"bad weekday and time", []OpeningInterval{opening(7, "11:00", "14:00"), opening(1, "later", "14:00")}, []string{"intervals[0].weekday", "intervals[1].openTime"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NormalizeSchedule(tt.intervals)
+			if len(tt.invalid) == 0 {
+				if err != nil {
+					t.Fatalf("NormalizeSchedule() = %v, want nil", err)
+				}
+				if len(normalized) != len(tt.intervals) {
+					t.Errorf("got %d intervals, want %d", len(normalized), len(tt.intervals))
+				}
+				return
+			}
+
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("NormalizeSchedule() = %v, want a validation error", err)
+			}
+			for _, field := range tt.invalid {
+				if _, ok := invalid.Fields[field]; !ok {
+					t.Errorf("%s isn't reported, got %v", field, invalid.Fields)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type DietaryFlag string
+
+const (
+	DietaryVegetarian       DietaryFlag = "vegetarian"
+	DietaryVegan            DietaryFlag = "vegan"
+	DietaryHalal            DietaryFlag = "halal"
+	DietaryKosher           DietaryFlag = "kosher"
+	DietaryGlutenFree       DietaryFlag = "gluten_free"
+	DietaryDairyFree        DietaryFlag = "dairy_free"
+	DietaryNutAllergy       DietaryFlag = "nut_allergy"
+	DietaryShellfishAllergy DietaryFlag = "shellfish_allergy"
+)
+
+var DietaryFlagValues = []DietaryFlag{
+	DietaryVegetarian, DietaryVegan, DietaryHalal, DietaryKosher,
+	DietaryGlutenFree, DietaryDairyFree, DietaryNutAllergy, DietaryShellfishAllergy,
+}
+
+const MaxSpecialRequestsLength = 500
+
+func IsValidDietaryFlag(flag DietaryFlag) bool {
+	for _, value := range DietaryFlagValues {
+		if flag == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Stored as a comma separated text column, sent as a json array
+type DietaryFlags []DietaryFlag
+
+func (f DietaryFlags) Value() (driver.Value, error) {
+	values := make([]string, len(f))
+	for i, flag := range f {
+		values[i] = string(flag)
+	}
+	return strings.Join(values, ","), nil
+}
+
+func (f *DietaryFlags) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("can't scan %T into DietaryFlags", value)
+	}
+
+	flags := DietaryFlags{}
+	for _, flag := range strings.Split(text, ",") {
+		if flag != "" {
+			flags = append(flags, DietaryFlag(flag))
+		}
+	}
+	*f = flags
+	return nil
+}
+
+func (DietaryFlags) GormDataType() string {
+	return "text"
+}
+
+// Check the party details of a reservation and drop repeated dietary flags
+func validateParty(reservation *Reservation, problems *ValidationError) {
+	if reservation.PartySize < 0 {
+		problems.Add("partySize", "must be at least 1")
+	}
+
+	seen := map[DietaryFlag]bool{}
+	flags := DietaryFlags{}
+	for _, flag := range reservation.DietaryFlags {
+		if !IsValidDietaryFlag(flag) {
+			problems.Add("dietaryFlags", fmt.Sprintf("%q is not a known flag", flag))
+			continue
+		}
+		if !seen[flag] {
+			seen[flag] = true
+			flags = append(flags, flag)
+		}
+	}
+	if reservation.DietaryFlags != nil {
+		reservation.DietaryFlags = flags
+	}
+
+	reservation.SpecialRequests = strings.TrimSpace(reservation.SpecialRequests)
+	if utf8.RuneCountInString(reservation.SpecialRequests) > MaxSpecialRequestsLength {
+		problems.Add("specialRequests", fmt.Sprintf("must be at most %d characters", MaxSpecialRequestsLength))
+	}
+}
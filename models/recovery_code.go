@@ -0,0 +1,77 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/punchanabu/redrice-backend-go/utils"
+	"gorm.io/gorm"
+)
+
+const RecoveryCodeCount = 10
+
+// One-time code that can be used instead of a TOTP code when the phone is lost
+type RecoveryCode struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `json:"userId" gorm:"index"`
+	CodeHash   string     `json:"-" gorm:"index"`
+	UsedAt     *time.Time `json:"usedAt"`
+	gorm.Model `json:"-" swaggerignore:"true"`
+}
+
+type RecoveryCodeHandler struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeHandler(db *gorm.DB) *RecoveryCodeHandler {
+	return &RecoveryCodeHandler{db}
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+// Throw away the old recovery codes of a user and create a fresh set
+func (h *RecoveryCodeHandler) ReplaceCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	rows := make([]RecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		rows = append(rows, RecoveryCode{UserID: userID, CodeHash: utils.HashToken(normalizeRecoveryCode(code))})
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Use up a recovery code, returns false if it doesn't exist or was already used
+func (h *RecoveryCodeHandler) UseCode(userID uint, code string) bool {
+	result := h.db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected > 0
+}
@@ -1,21 +1,41 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Reservation struct {
-	ID           uint       `gorm:"primaryKey"`
-	DateTime     time.Time  `json:"dateTime"`
-	TableNum     int        `json:"tableNum"`
-	ExitTime     time.Time  `json:"exitTime"`
-	UserID       uint       `json:"userId"`
-	User         User       `gorm:"foreignKey:UserID" json:"user"`
-	RestaurantID uint       `json:"restaurantId"`
-	Restaurant   Restaurant `gorm:"foreignKey:RestaurantID" json:"restaurant"`
-	gorm.Model   `json:"-" swaggerignore:"true"`
+	ID                uint              `gorm:"primaryKey"`
+	DateTime          time.Time         `json:"dateTime"`
+	TableNum          int               `json:"tableNum"`
+	TableID           uint              `json:"tableId" gorm:"index"`
+	ExitTime          time.Time         `json:"exitTime"`
+	PartySize         int               `json:"partySize" gorm:"default:1" example:"2"`
+	DietaryFlags      DietaryFlags      `json:"dietaryFlags" swaggertype:"array,string" example:"vegetarian,nut_allergy"`
+	SpecialRequests   string            `json:"specialRequests" example:"High chair please, it's a birthday"`
+	Status            ReservationStatus `json:"status" gorm:"default:pending;index"`
+	StatusChangedAt   *time.Time        `json:"statusChangedAt"`
+	StatusChangedByID *uint             `json:"statusChangedById"`
+	Sequence          int               `json:"-" gorm:"default:0"`
+	SeriesID          *uint             `json:"seriesId" gorm:"index"`
+	UserID            uint              `json:"userId"`
+	User              User              `gorm:"foreignKey:UserID" json:"user"`
+	RestaurantID      uint              `json:"restaurantId"`
+	Restaurant        Restaurant        `gorm:"foreignKey:RestaurantID" json:"restaurant"`
+	gorm.Model        `json:"-" swaggerignore:"true"`
+}
+
+// Nested users only show their public profile
+func (r Reservation) MarshalJSON() ([]byte, error) {
+	type reservation Reservation
+	return json.Marshal(struct {
+		reservation
+		User PublicUser `json:"user"`
+	}{reservation(r), r.User.PublicView()})
 }
 
 type ReservationHandler struct {
@@ -26,10 +46,39 @@ func NewReservationHandler(db *gorm.DB) *ReservationHandler {
 	return &ReservationHandler{db}
 }
 
-func (h *ReservationHandler) CreateReservation(userID uint, reservation *Reservation) error {
+// Book the reservation on the requested table, or pick a free one when TableNum is 0.
+// Returns a *ReservationConflictError when the slot is already taken, and a *BookingLimitError
+// when enforceLimit is set and the user can't have more active bookings.
+func (h *ReservationHandler) CreateReservation(userID uint, reservation *Reservation, enforceLimit bool) error {
 	reservation.UserID = userID
+	reservation.TableID = 0
+	reservation.Status = StatusPending
+	reservation.StatusChangedAt = nil
+	reservation.StatusChangedByID = nil
+	reservation.SeriesID = nil
+	if reservation.PartySize == 0 {
+		reservation.PartySize = 1
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if enforceLimit {
+			if err := enforceBookingLimit(tx, userID, reservation.RestaurantID); err != nil {
+				return err
+			}
+		}
+		if err := validateSlot(tx, reservation, true); err != nil {
+			return err
+		}
+		if err := assignTable(tx, reservation, reservation.PartySize, 0); err != nil {
+			return err
+		}
 
-	if err := h.db.Create(reservation).Error; err != nil {
+		if err := tx.Create(reservation).Error; err != nil {
+			return err
+		}
+		return recordHistory(tx, reservation.ID, HistoryCreated, userID, nil, reservation)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -48,14 +97,136 @@ func (h *ReservationHandler) GetReservations() ([]Reservation, error) {
 	return reservations, result.Error
 }
 
-func (h *ReservationHandler) UpdateReservation(id uint, reservation *Reservation) error {
-	result := h.db.Model(&Reservation{}).Where("id = ?", id).Updates(reservation)
-	return result.Error
+// Apply the non-zero fields of reservation. When the time, table or restaurant changes,
+// the new slot is checked against other bookings the same way as when creating, and the
+// guest has to keep to the cancellation deadline. actorID is who made the change.
+func (h *ReservationHandler) UpdateReservation(id uint, reservation *Reservation, actorID uint) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		return updateReservation(tx, id, reservation, actorID)
+	})
 }
 
-func (h *ReservationHandler) DeleteReservation(id uint) error {
-	result := h.db.Delete(&Reservation{}, id)
-	return result.Error
+func updateReservation(tx *gorm.DB, id uint, reservation *Reservation, actorID uint) error {
+	var current Reservation
+	if err := tx.First(&current, id).Error; err != nil {
+		return err
+	}
+	if !current.Status.IsActive() {
+		return ErrReservationNotActive
+	}
+
+	problems := &ValidationError{}
+	validateParty(reservation, problems)
+	if err := problems.OrNil(); err != nil {
+		return err
+	}
+
+	moved := !reservation.DateTime.IsZero() || !reservation.ExitTime.IsZero() ||
+		reservation.TableNum != 0 || reservation.RestaurantID != 0 || reservation.PartySize != 0
+
+	// Moving a booking frees its old slot like cancelling does, so guests have the same deadline
+	rebooked := (!reservation.DateTime.IsZero() && !reservation.DateTime.Equal(current.DateTime)) ||
+		(!reservation.ExitTime.IsZero() && !reservation.ExitTime.Equal(current.ExitTime)) ||
+		(reservation.TableNum != 0 && reservation.TableNum != current.TableNum) ||
+		(reservation.RestaurantID != 0 && reservation.RestaurantID != current.RestaurantID)
+	if rebooked && actorID == current.UserID {
+		if err := checkCancellationWindow(tx, &current); err != nil {
+			return err
+		}
+	}
+
+	if moved {
+		slot := current
+		if !reservation.DateTime.IsZero() {
+			slot.DateTime = reservation.DateTime
+		}
+		if !reservation.ExitTime.IsZero() {
+			slot.ExitTime = reservation.ExitTime
+		}
+		if reservation.RestaurantID != 0 && reservation.RestaurantID != current.RestaurantID {
+			slot.RestaurantID = reservation.RestaurantID
+			slot.TableNum = 0
+		}
+		if reservation.TableNum != 0 {
+			slot.TableNum = reservation.TableNum
+		}
+		if reservation.PartySize != 0 {
+			slot.PartySize = reservation.PartySize
+		}
+
+		if err := validateSlot(tx, &slot, !reservation.DateTime.IsZero()); err != nil {
+			return err
+		}
+
+		// A bigger party that no longer fits gets moved to another table, unless a table was asked for
+		err := assignTable(tx, &slot, slot.PartySize, id)
+		var tooSmall *ValidationError
+		if errors.As(err, &tooSmall) && reservation.PartySize != 0 && reservation.TableNum == 0 && slot.TableNum != 0 {
+			slot.TableNum = 0
+			err = assignTable(tx, &slot, slot.PartySize, id)
+		}
+		if err != nil {
+			return err
+		}
+		reservation.TableID = slot.TableID
+		reservation.TableNum = slot.TableNum
+	} else {
+		reservation.TableID = 0
+	}
+	reservation.SeriesID = nil
+
+	if err := tx.Model(&Reservation{}).Where("id = ?", id).Updates(reservation).Error; err != nil {
+		return err
+	}
+	// A series stays at one restaurant, an occurrence moved elsewhere leaves it
+	if reservation.RestaurantID != 0 && reservation.RestaurantID != current.RestaurantID && current.SeriesID != nil {
+		if err := tx.Model(&Reservation{}).Where("id = ?", id).Update("series_id", nil).Error; err != nil {
+			return err
+		}
+	}
+	if err := bumpSequence(tx, id); err != nil {
+		return err
+	}
+
+	var updated Reservation
+	if err := tx.First(&updated, id).Error; err != nil {
+		return err
+	}
+	return recordHistory(tx, id, HistoryUpdated, actorID, &current, &updated)
+}
+
+// Delete the reservation, if it was still holding a table the waitlist gets offered the time.
+// Guests deleting their own booking have to keep to the cancellation deadline like when cancelling.
+func (h *ReservationHandler) DeleteReservation(id uint, actorID uint) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		var reservation Reservation
+		if err := tx.First(&reservation, id).Error; err != nil {
+			return err
+		}
+
+		if actorID == reservation.UserID && reservation.Status.IsActive() {
+			if err := checkCancellationWindow(tx, &reservation); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Delete(&Reservation{}, id).Error; err != nil {
+			return err
+		}
+		if err := recordHistory(tx, id, HistoryDeleted, actorID, &reservation, nil); err != nil {
+			return err
+		}
+
+		if reservation.Status.IsActive() {
+			return offerFreedSlot(tx, reservation.RestaurantID, reservation.DateTime, reservation.ExitTime)
+		}
+		return nil
+	})
+}
+
+// Calendar apps only replace an event when its SEQUENCE goes up
+func bumpSequence(tx *gorm.DB, id uint) error {
+	return tx.Model(&Reservation{}).Where("id = ?", id).UpdateColumn("sequence", gorm.Expr("sequence + ?", 1)).Error
 }
 
 func (handler *ReservationHandler) GetReservationsByUserID(userID uint) ([]Reservation, error) {
@@ -67,3 +238,43 @@ func (handler *ReservationHandler) GetReservationsByUserID(userID uint) ([]Reser
 	}
 	return reservations, nil
 }
+
+func (h *ReservationHandler) GetReservationsByRestaurantID(restaurantID uint, filter ReservationFilter) ([]Reservation, error) {
+	query := h.db.Preload("User").Preload("Restaurant").Where("restaurant_id = ?", restaurantID)
+	if !filter.From.IsZero() {
+		query = query.Where("exit_time > ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("date_time < ?", filter.To)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.TableNum != 0 {
+		query = query.Where("table_num = ?", filter.TableNum)
+	}
+
+	var reservations []Reservation
+	result := query.Order("date_time").Find(&reservations)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return reservations, nil
+}
+
+// Reservations for a calendar feed, starting from since. Deleted ones are included
+// so calendars that already have them can show them as cancelled.
+func (h *ReservationHandler) GetCalendarReservations(userID uint, restaurantID uint, since time.Time) ([]Reservation, error) {
+	query := h.db.Unscoped().Preload("User").Preload("Restaurant").Where("exit_time >= ?", since)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if restaurantID != 0 {
+		query = query.Where("restaurant_id = ?", restaurantID)
+	}
+
+	var reservations []Reservation
+	result := query.Order("date_time").Find(&reservations)
+	return reservations, result.Error
+}
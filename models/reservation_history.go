@@ -0,0 +1,147 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type HistoryAction string
+
+const (
+	HistoryCreated       HistoryAction = "created"
+	HistoryUpdated       HistoryAction = "updated"
+	HistoryStatusChanged HistoryAction = "status_changed"
+	HistoryDeleted       HistoryAction = "deleted"
+)
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Stored as a json text column, keyed by the json name of the field
+type FieldChanges map[string]FieldChange
+
+func (f FieldChanges) Value() (driver.Value, error) {
+	b, err := json.Marshal(f)
+	return string(b), err
+}
+
+func (f *FieldChanges) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*f = FieldChanges{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("can't scan %T into FieldChanges", value)
+	}
+	return json.Unmarshal(b, f)
+}
+
+func (FieldChanges) GormDataType() string {
+	return "text"
+}
+
+// One change to a reservation. Entries are only ever added, never updated or deleted,
+// and they stay after the reservation itself is deleted.
+type ReservationHistory struct {
+	ID            uint          `gorm:"primaryKey"`
+	ReservationID uint          `json:"reservationId" gorm:"index"`
+	Action        HistoryAction `json:"action" example:"updated"`
+	ActorID       uint          `json:"actorId"`
+	Actor         User          `gorm:"foreignKey:ActorID" json:"actor"`
+	At            time.Time     `json:"at"`
+	Changes       FieldChanges  `json:"changes" swaggertype:"object"`
+	CreatedAt     time.Time     `json:"-"`
+}
+
+// Nested users only show their public profile
+func (e ReservationHistory) MarshalJSON() ([]byte, error) {
+	type reservationHistory ReservationHistory
+	return json.Marshal(struct {
+		reservationHistory
+		Actor PublicUser `json:"actor"`
+	}{reservationHistory(e), e.Actor.PublicView()})
+}
+
+// The fields the history keeps track of, by their json name
+func historyFields(r *Reservation) map[string]interface{} {
+	if r == nil {
+		return map[string]interface{}{}
+	}
+
+	flags := make([]string, len(r.DietaryFlags))
+	for i, flag := range r.DietaryFlags {
+		flags[i] = string(flag)
+	}
+
+	return map[string]interface{}{
+		"userId":          r.UserID,
+		"restaurantId":    r.RestaurantID,
+		"dateTime":        r.DateTime.UTC().Format(time.RFC3339),
+		"exitTime":        r.ExitTime.UTC().Format(time.RFC3339),
+		"tableNum":        r.TableNum,
+		"partySize":       r.PartySize,
+		"dietaryFlags":    flags,
+		"specialRequests": r.SpecialRequests,
+		"status":          string(r.Status),
+	}
+}
+
+// The fields that differ between before and after, nil stands for a reservation that doesn't exist
+func diffReservations(before *Reservation, after *Reservation) FieldChanges {
+	from, to := historyFields(before), historyFields(after)
+
+	changes := FieldChanges{}
+	for field := range from {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			changes[field] = FieldChange{From: from[field], To: to[field]}
+		}
+	}
+	for field := range to {
+		if _, ok := from[field]; !ok {
+			changes[field] = FieldChange{From: nil, To: to[field]}
+		}
+	}
+	return changes
+}
+
+// Add a history entry for the reservation. Must run in the same transaction as the change,
+// so there is never a change without an entry. Updates that changed nothing aren't recorded.
+func recordHistory(tx *gorm.DB, reservationID uint, action HistoryAction, actorID uint, before *Reservation, after *Reservation) error {
+	changes := diffReservations(before, after)
+	if action == HistoryUpdated && len(changes) == 0 {
+		return nil
+	}
+
+	return tx.Create(&ReservationHistory{
+		ReservationID: reservationID,
+		Action:        action,
+		ActorID:       actorID,
+		At:            time.Now(),
+		Changes:       changes,
+	}).Error
+}
+
+func (h *ReservationHandler) GetHistory(reservationID uint) ([]ReservationHistory, error) {
+	var history []ReservationHistory
+	result := h.db.Preload("Actor").Where("reservation_id = ?", reservationID).Order("at, id").Find(&history)
+	return history, result.Error
+}
+
+// Also finds deleted reservations, so their history can still be looked up
+func (h *ReservationHandler) GetReservationWithDeleted(id uint) (*Reservation, error) {
+	var reservation Reservation
+	result := h.db.Unscoped().First(&reservation, id)
+	return &reservation, result.Error
+}
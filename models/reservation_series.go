@@ -0,0 +1,446 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SeriesFrequency string
+
+const (
+	SeriesWeekly  SeriesFrequency = "weekly"
+	SeriesMonthly SeriesFrequency = "monthly"
+)
+
+// Which occurrences of a series an edit or a cancellation applies to
+type SeriesScope string
+
+const (
+	ScopeThis      SeriesScope = "this"
+	ScopeFollowing SeriesScope = "following"
+	ScopeAll       SeriesScope = "all"
+)
+
+// A year of weekly bookings
+const MaxSeriesOccurrences = 52
+
+var (
+	ErrNotInSeries         = errors.New("reservation is not part of a series")
+	ErrNothingBooked       = errors.New("none of the occurrences could be booked")
+	ErrOccurrenceForbidden = errors.New("not allowed to change this occurrence")
+)
+
+// A booking that repeats every Interval weeks or months, like RRULE's FREQ, INTERVAL, COUNT and UNTIL.
+// DateTime and ExitTime are the first occurrence, the others keep its wall clock time in the
+// restaurant's time zone. Monthly series skip months that don't have the day, like RFC 5545 does.
+type ReservationSeries struct {
+	ID              uint            `gorm:"primaryKey"`
+	UserID          uint            `json:"userId" gorm:"index"`
+	User            User            `gorm:"foreignKey:UserID" json:"user"`
+	RestaurantID    uint            `json:"restaurantId" gorm:"index"`
+	Restaurant      Restaurant      `gorm:"foreignKey:RestaurantID" json:"restaurant"`
+	Frequency       SeriesFrequency `json:"frequency" example:"weekly"`
+	Interval        int             `json:"interval" gorm:"default:1" example:"1"`
+	Count           int             `json:"count" example:"10"`
+	Until           *time.Time      `json:"until"`
+	DateTime        time.Time       `json:"dateTime"`
+	ExitTime        time.Time       `json:"exitTime"`
+	TableNum        int             `json:"tableNum"`
+	PartySize       int             `json:"partySize" example:"8"`
+	DietaryFlags    DietaryFlags    `json:"dietaryFlags" swaggertype:"array,string" example:"vegetarian"`
+	SpecialRequests string          `json:"specialRequests" example:"Quiet corner for a team lunch"`
+	Reservations    []Reservation   `gorm:"foreignKey:SeriesID" json:"reservations"`
+	gorm.Model      `json:"-" swaggerignore:"true"`
+}
+
+// Nested users only show their public profile
+func (s ReservationSeries) MarshalJSON() ([]byte, error) {
+	type reservationSeries ReservationSeries
+	return json.Marshal(struct {
+		reservationSeries
+		User PublicUser `json:"user"`
+	}{reservationSeries(s), s.User.PublicView()})
+}
+
+// What went wrong with one occurrence, the other occurrences still go ahead
+type OccurrenceConflict struct {
+	ReservationID uint                      `json:"reservationId,omitempty"`
+	DateTime      time.Time                 `json:"dateTime"`
+	ExitTime      time.Time                 `json:"exitTime"`
+	Error         string                    `json:"error" example:"table 4 is already booked from 2024-05-03T12:00:00Z to 2024-05-03T14:00:00Z"`
+	Fields        map[string]string         `json:"fields,omitempty"`
+	Conflict      *ReservationConflictError `json:"conflict,omitempty"`
+}
+
+type SeriesChangeResult struct {
+	Changed   []Reservation        `json:"changed"`
+	Conflicts []OccurrenceConflict `json:"conflicts"`
+}
+
+// Turn the errors one occurrence can run into into a report. Anything unexpected, like
+// the database going away, isn't about the occurrence and is returned as is.
+func occurrenceConflict(reservation *Reservation, err error) (*OccurrenceConflict, error) {
+	report := &OccurrenceConflict{
+		ReservationID: reservation.ID,
+		DateTime:      reservation.DateTime,
+		ExitTime:      reservation.ExitTime,
+		Error:         err.Error(),
+	}
+
+	var conflict *ReservationConflictError
+	var invalid *ValidationError
+	var limit *BookingLimitError
+	var restricted *BookingRestrictedError
+	var tooLate *CancellationWindowError
+	var transition *InvalidTransitionError
+	switch {
+	case errors.As(err, &conflict):
+		report.Conflict = conflict
+	case errors.As(err, &invalid):
+		report.Fields = invalid.Fields
+	case errors.As(err, &limit), errors.As(err, &restricted), errors.As(err, &tooLate), errors.As(err, &transition),
+		errors.Is(err, ErrTableNotFound), errors.Is(err, ErrNoTables), errors.Is(err, ErrReservationNotActive),
+		errors.Is(err, ErrOccurrenceForbidden):
+	default:
+		return nil, err
+	}
+	return report, nil
+}
+
+// Check the pattern itself, the occurrences are checked one by one when they are booked
+func (s *ReservationSeries) validate() error {
+	problems := &ValidationError{}
+
+	if s.Frequency != SeriesWeekly && s.Frequency != SeriesMonthly {
+		problems.Add("frequency", "must be weekly or monthly")
+	}
+	if s.Interval == 0 {
+		s.Interval = 1
+	}
+	if s.Interval < 0 || s.Interval > 12 {
+		problems.Add("interval", "must be between 1 and 12")
+	}
+	if (s.Count == 0) == (s.Until == nil) {
+		problems.Add("count", "either count or until is required")
+	} else if s.Count < 0 || s.Count > MaxSeriesOccurrences {
+		problems.Add("count", "must be between 1 and 52")
+	}
+	if s.DateTime.IsZero() {
+		problems.Add("dateTime", "is required")
+	} else if s.Until != nil && s.Until.Before(s.DateTime) {
+		problems.Add("until", "must be after dateTime")
+	}
+	if s.ExitTime.IsZero() {
+		problems.Add("exitTime", "is required")
+	} else if !s.ExitTime.After(s.DateTime) {
+		problems.Add("exitTime", "must be after dateTime")
+	}
+
+	return problems.OrNil()
+}
+
+// The occurrences of the series as reservations that haven't been booked yet
+func (s *ReservationSeries) expand(loc *time.Location) ([]Reservation, error) {
+	first := s.DateTime.In(loc)
+	duration := s.ExitTime.Sub(s.DateTime)
+
+	var occurrences []Reservation
+	for k := 0; s.Count == 0 || len(occurrences) < s.Count; k++ {
+		var start time.Time
+		if s.Frequency == SeriesWeekly {
+			start = time.Date(first.Year(), first.Month(), first.Day()+7*s.Interval*k, first.Hour(), first.Minute(), first.Second(), 0, loc)
+		} else {
+			start = time.Date(first.Year(), first.Month()+time.Month(s.Interval*k), first.Day(), first.Hour(), first.Minute(), first.Second(), 0, loc)
+			// The 31st of a month with 30 days rolls over into the next month
+			if start.Day() != first.Day() {
+				continue
+			}
+		}
+
+		if s.Until != nil && start.After(*s.Until) {
+			break
+		}
+		if len(occurrences) == MaxSeriesOccurrences {
+			return nil, &ValidationError{Fields: map[string]string{"until": "makes more than 52 occurrences"}}
+		}
+
+		occurrences = append(occurrences, Reservation{
+			UserID:          s.UserID,
+			RestaurantID:    s.RestaurantID,
+			DateTime:        start,
+			ExitTime:        start.Add(duration),
+			TableNum:        s.TableNum,
+			PartySize:       s.PartySize,
+			DietaryFlags:    s.DietaryFlags,
+			SpecialRequests: s.SpecialRequests,
+			Status:          StatusPending,
+		})
+	}
+	return occurrences, nil
+}
+
+// Create the series and book every occurrence that is free, each the same way as CreateReservation.
+// For the booking limit the whole series counts as one booking, so it's checked once up front and
+// a user at the limit gets the *BookingLimitError without anything being booked. Occurrences that
+// can't be booked are returned as conflicts. When none of them can be booked nothing is saved and
+// ErrNothingBooked is returned together with the conflicts.
+func (h *ReservationHandler) CreateSeries(userID uint, series *ReservationSeries, enforceLimit bool) ([]OccurrenceConflict, error) {
+	series.UserID = userID
+	series.Reservations = nil
+	if series.PartySize == 0 {
+		series.PartySize = 1
+	}
+	if err := series.validate(); err != nil {
+		return nil, err
+	}
+
+	conflicts := []OccurrenceConflict{}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var restaurant Restaurant
+		if err := tx.First(&restaurant, series.RestaurantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ValidationError{Fields: map[string]string{"restaurantId": "does not exist"}}
+			}
+			return err
+		}
+
+		occurrences, err := series.expand(restaurant.Location())
+		if err != nil {
+			return err
+		}
+
+		if enforceLimit {
+			if err := enforceBookingLimit(tx, userID, series.RestaurantID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Omit("Reservations").Create(series).Error; err != nil {
+			return err
+		}
+
+		booked := 0
+		for i := range occurrences {
+			occurrence := &occurrences[i]
+			occurrence.SeriesID = &series.ID
+
+			// Every occurrence gets a savepoint, so one that clashes doesn't undo the others
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				if err := validateSlot(tx, occurrence, true); err != nil {
+					return err
+				}
+				if err := assignTable(tx, occurrence, occurrence.PartySize, 0); err != nil {
+					return err
+				}
+				if err := tx.Create(occurrence).Error; err != nil {
+					return err
+				}
+				return recordHistory(tx, occurrence.ID, HistoryCreated, userID, nil, occurrence)
+			})
+			if err != nil {
+				occurrence.TableNum = series.TableNum
+				report, err := occurrenceConflict(occurrence, err)
+				if err != nil {
+					return err
+				}
+				conflicts = append(conflicts, *report)
+				continue
+			}
+			booked++
+		}
+
+		if booked == 0 {
+			return ErrNothingBooked
+		}
+		return nil
+	})
+	if err != nil {
+		return conflicts, err
+	}
+
+	return conflicts, h.loadSeries(series, series.ID)
+}
+
+func (h *ReservationHandler) loadSeries(series *ReservationSeries, id uint) error {
+	return h.db.Preload("User").Preload("Restaurant").
+		Preload("Reservations", func(db *gorm.DB) *gorm.DB { return db.Order("date_time") }).
+		Preload("Reservations.User").Preload("Reservations.Restaurant").
+		First(series, id).Error
+}
+
+func (h *ReservationHandler) GetSeries(id uint) (*ReservationSeries, error) {
+	var series ReservationSeries
+	return &series, h.loadSeries(&series, id)
+}
+
+// The occurrences an edit or cancellation of reservation applies to, only those that are still
+// active and at the same restaurant. Occurrences that already happened are left alone unless they
+// are the one asked for. The rows stay locked until the transaction ends.
+func seriesOccurrences(tx *gorm.DB, reservation *Reservation, scope SeriesScope) ([]Reservation, error) {
+	if scope == ScopeThis {
+		return []Reservation{*reservation}, nil
+	}
+	if reservation.SeriesID == nil {
+		return nil, ErrNotInSeries
+	}
+
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("series_id = ? AND restaurant_id = ? AND status IN ?", *reservation.SeriesID, reservation.RestaurantID, ActiveStatuses)
+	if scope == ScopeFollowing {
+		query = query.Where("date_time >= ?", reservation.DateTime)
+	} else {
+		query = query.Where("(date_time >= ? OR id = ?)", time.Now(), reservation.ID)
+	}
+
+	var occurrences []Reservation
+	result := query.Order("date_time").Find(&occurrences)
+	return occurrences, result.Error
+}
+
+// Move a time by the same number of days and to the same wall clock time as from moved to to,
+// so a series keeps its time of day across daylight saving changes
+func shiftWallClock(t time.Time, from time.Time, to time.Time, loc *time.Location) time.Time {
+	t, from, to = t.In(loc), from.In(loc), to.In(loc)
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(toDay.Sub(fromDay).Hours() / 24)
+	return time.Date(t.Year(), t.Month(), t.Day()+days, to.Hour(), to.Minute(), to.Second(), 0, loc)
+}
+
+// Run change on every occurrence in scope inside one transaction, each in its own savepoint, so
+// an occurrence that can't be changed is reported while anything unexpected undoes all of them.
+// Occurrences allowed rejects are reported as ErrOccurrenceForbidden.
+func (h *ReservationHandler) changeSeries(reservation *Reservation, scope SeriesScope, allowed func(*Reservation) bool,
+	change func(tx *gorm.DB, occurrence *Reservation) error, done func(tx *gorm.DB, changed int) error) (*SeriesChangeResult, error) {
+	var changedIDs []uint
+	conflicts := []OccurrenceConflict{}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		occurrences, err := seriesOccurrences(tx, reservation, scope)
+		if err != nil {
+			return err
+		}
+
+		for i := range occurrences {
+			occurrence := &occurrences[i]
+
+			err := ErrOccurrenceForbidden
+			if allowed(occurrence) {
+				err = tx.Transaction(func(tx *gorm.DB) error {
+					return change(tx, occurrence)
+				})
+			}
+			if err != nil {
+				report, err := occurrenceConflict(occurrence, err)
+				if err != nil {
+					return err
+				}
+				conflicts = append(conflicts, *report)
+				continue
+			}
+			changedIDs = append(changedIDs, occurrence.ID)
+		}
+
+		return done(tx, len(changedIDs))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SeriesChangeResult{Changed: []Reservation{}, Conflicts: conflicts}
+	if len(changedIDs) > 0 {
+		if err := h.db.Preload("User").Preload("Restaurant").Order("date_time").Find(&result.Changed, changedIDs).Error; err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Apply patch to the occurrences in scope the same way as UpdateReservation does to one. A new
+// dateTime or exitTime given for reservation moves every occurrence by the same days and to the
+// same time of day. Occurrences that can't be moved keep their old slot and are returned as conflicts.
+func (h *ReservationHandler) UpdateSeries(reservation *Reservation, patch *Reservation, scope SeriesScope, actorID uint, allowed func(*Reservation) bool) (*SeriesChangeResult, error) {
+	loc := reservation.Restaurant.Location()
+
+	moved := !patch.DateTime.IsZero() || !patch.ExitTime.IsZero()
+
+	// Move a booking of the series the way the patch moves the reservation it was made on
+	shift := func(dateTime time.Time, exitTime time.Time) (time.Time, time.Time) {
+		start, exit := dateTime, exitTime
+		if !patch.DateTime.IsZero() {
+			start = shiftWallClock(dateTime, reservation.DateTime, patch.DateTime, loc)
+			exit = start.Add(exitTime.Sub(dateTime))
+		}
+		if !patch.ExitTime.IsZero() {
+			base := reservation.DateTime
+			if !patch.DateTime.IsZero() {
+				base = patch.DateTime
+			}
+			exit = start.Add(patch.ExitTime.Sub(base))
+		}
+		return start, exit
+	}
+
+	change := func(tx *gorm.DB, occurrence *Reservation) error {
+		update := *patch
+		update.RestaurantID = 0
+
+		if moved {
+			update.DateTime, update.ExitTime = shift(occurrence.DateTime, occurrence.ExitTime)
+		}
+
+		err := updateReservation(tx, occurrence.ID, &update, actorID)
+		if err != nil && !update.DateTime.IsZero() {
+			// Report the slot it couldn't be moved to
+			occurrence.DateTime, occurrence.ExitTime = update.DateTime, update.ExitTime
+		}
+		return err
+	}
+
+	// The series keeps describing the bookings that are still coming
+	done := func(tx *gorm.DB, changed int) error {
+		if scope == ScopeThis || changed == 0 {
+			return nil
+		}
+		template := ReservationSeries{
+			TableNum:        patch.TableNum,
+			PartySize:       patch.PartySize,
+			DietaryFlags:    patch.DietaryFlags,
+			SpecialRequests: patch.SpecialRequests,
+		}
+
+		if moved {
+			var series ReservationSeries
+			if err := tx.First(&series, *reservation.SeriesID).Error; err != nil {
+				return err
+			}
+			template.DateTime, template.ExitTime = shift(series.DateTime, series.ExitTime)
+			// The end moves along, or the last occurrence could fall after it
+			if series.Until != nil && !patch.DateTime.IsZero() {
+				until := shiftWallClock(*series.Until, reservation.DateTime, patch.DateTime, loc)
+				template.Until = &until
+			}
+		}
+
+		return tx.Model(&ReservationSeries{}).Where("id = ?", *reservation.SeriesID).Updates(&template).Error
+	}
+
+	return h.changeSeries(reservation, scope, allowed, change, done)
+}
+
+// Cancel the occurrences in scope the same way as TransitionReservation does one, guests still
+// have to keep to the cancellation deadline so the next occurrence may be too late to cancel
+func (h *ReservationHandler) CancelSeries(reservation *Reservation, scope SeriesScope, actorID uint, allowed func(*Reservation) bool) (*SeriesChangeResult, error) {
+	change := func(tx *gorm.DB, occurrence *Reservation) error {
+		return transitionReservation(tx, occurrence.ID, StatusCancelled, actorID)
+	}
+	done := func(tx *gorm.DB, changed int) error {
+		return nil
+	}
+
+	return h.changeSeries(reservation, scope, allowed, change, done)
+}
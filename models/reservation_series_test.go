@@ -0,0 +1,196 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSeriesExpand(t *testing.T) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := func(loc *time.Location, year int, month time.Month, day int, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, loc)
+	}
+	until := func(t time.Time) *time.Time {
+		return &t
+	}
+
+	tests := []struct {
+		name   string
+		loc    *time.Location
+		series ReservationSeries
+		want   []time.Time
+		err    bool
+	}{
+		{
+			name:   "weekly by count",
+			loc:    bangkok,
+			series: ReservationSeries{Frequency: SeriesWeekly, Interval: 1, Count: 3, DateTime: date(bangkok, 2024, 5, 3, 12)},
+			want:   []time.Time{date(bangkok, 2024, 5, 3, 12), date(bangkok, 2024, 5, 10, 12), date(bangkok, 2024, 5, 17, 12)},
+		},
+		{
+			name:   "every other week",
+			loc:    bangkok,
+			series: ReservationSeries{Frequency: SeriesWeekly, Interval: 2, Count: 3, DateTime: date(bangkok, 2024, 5, 31, 12)},
+			want:   []time.Time{date(bangkok, 2024, 5, 31, 12), date(bangkok, 2024, 6, 14, 12), date(bangkok, 2024, 6, 28, 12)},
+		},
+		{
+			name:   "weekly keeps the time of day over daylight saving",
+			loc:    newYork,
+			series: ReservationSeries{Frequency: SeriesWeekly, Interval: 1, Count: 3, DateTime: date(newYork, 2024, 10, 25, 12)},
+			want:   []time.Time{date(newYork, 2024, 10, 25, 12), date(newYork, 2024, 11, 1, 12), date(newYork, 2024, 11, 8, 12)},
+		},
+		{
+			name:   "until is inclusive",
+			loc:    bangkok,
+			series: ReservationSeries{Frequency: SeriesWeekly, Interval: 1, Until: until(date(bangkok, 2024, 5, 17, 12)), DateTime: date(bangkok, 2024, 5, 3, 12)},
+			want:   []time.Time{date(bangkok, 2024, 5, 3, 12), date(bangkok, 2024, 5, 10, 12), date(bangkok, 2024, 5, 17, 12)},
+		},
+		{
+			name:   "until before the next start",
+			loc:    bangkok,
+			series: ReservationSeries{Frequency: SeriesWeekly, Interval: 1, Until: until(date(bangkok, 2024, 5, 17, 11)), DateTime: date(bangkok, 2024, 5, 3, 12)},
+			want:   []time.Time{date(bangkok, 2024, 5, 3, 12), date(bangkok, 2024, 5, 10, 12)},
+		},
+		{
+			name:   "monthly on the 31st skips short months",
+			loc:    bangkok,
+			series: ReservationSeries{Frequency: SeriesMonthly, Interval: 1, Count: 4, DateTime: date(bangkok, 2024, 1, 31, 19)},
+			want:   []time.Time{date(bangkok, 2024, 1, 31, 19), date(bangkok, 2024, 3, 31, 19), date(bangkok, 2024, 5, 31, 19), date(bangkok, 2024, 7, 31, 19)},
+		},
+		{
+			name:   "monthly on the 29th of february",
+			loc:    bangkok,
+			series: ReservationSeries{Frequency: SeriesMonthly, Interval: 12, Until: until(date(bangkok, 2033, 1, 1, 0)), DateTime: date(bangkok, 2024, 2, 29, 19)},
+			want:   []time.Time{date(bangkok, 2024, 2, 29, 19), date(bangkok, 2028, 2, 29, 19), date(bangkok, 2032, 2, 29, 19)},
+		},
+		{
+			name:   "until that makes too many occurrences",
+			loc:    bangkok,
+			series: ReservationSeries{Frequency: SeriesWeekly, Interval: 1, Until: until(date(bangkok, 2026, 1, 1, 0)), DateTime: date(bangkok, 2024, 5, 3, 12)},
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.series.ExitTime = tt.series.DateTime.Add(2 * time.Hour)
+			occurrences, err := tt.series.expand(tt.loc)
+			if tt.err {
+				var invalid *ValidationError
+				if !errors.As(err, &invalid) {
+					t.Fatalf("expand() error = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(occurrences) != len(tt.want) {
+				t.Fatalf("got %d occurrences, want %d", len(occurrences), len(tt.want))
+			}
+			for i, occurrence := range occurrences {
+				if !occurrence.DateTime.Equal(tt.want[i]) {
+					t.Errorf("occurrence %d starts at %s, want %s", i, occurrence.DateTime, tt.want[i])
+				}
+				if occurrence.ExitTime.Sub(occurrence.DateTime) != 2*time.Hour {
+					t.Errorf("occurrence %d lasts %s", i, occurrence.ExitTime.Sub(occurrence.DateTime))
+				}
+				if occurrence.Status != StatusPending {
+					t.Errorf("occurrence %d is %s", i, occurrence.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestSeriesValidate(t *testing.T) {
+	start := time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	until := start.AddDate(0, 1, 0)
+	before := start.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name   string
+		series ReservationSeries
+		fields []string
+	}{
+		{"valid with count", ReservationSeries{Frequency: SeriesWeekly, Count: 10}, nil},
+		{"valid with until", ReservationSeries{Frequency: SeriesMonthly, Until: &until}, nil},
+		{"unknown frequency", ReservationSeries{Frequency: "daily", Count: 10}, []string{"frequency"}},
+		{"count and until", ReservationSeries{Frequency: SeriesWeekly, Count: 10, Until: &until}, []string{"count"}},
+		{"neither count nor until", ReservationSeries{Frequency: SeriesWeekly}, []string{"count"}},
+		{"too many", ReservationSeries{Frequency: SeriesWeekly, Count: 53}, []string{"count"}},
+		{"interval too big", ReservationSeries{Frequency: SeriesWeekly, Interval: 13, Count: 2}, []string{"interval"}},
+		{"until before start", ReservationSeries{Frequency: SeriesWeekly, Until: &before}, []string{"until"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.series.DateTime = start
+			tt.series.ExitTime = start.Add(time.Hour)
+
+			err := tt.series.validate()
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("validate() = %v, want nil", err)
+				}
+				if tt.series.Interval != 1 {
+					t.Errorf("interval defaults to %d, want 1", tt.series.Interval)
+				}
+				return
+			}
+
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("validate() = %v, want a validation error", err)
+			}
+			for _, field := range tt.fields {
+				if _, ok := invalid.Fields[field]; !ok {
+					t.Errorf("%s isn't reported, got %v", field, invalid.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestShiftWallClock(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, newYork)
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		from time.Time
+		to   time.Time
+		want time.Time
+	}{
+		{"same day, later", at(5, 10, 12, 0), at(5, 3, 12, 0), at(5, 3, 13, 30), at(5, 10, 13, 30)},
+		{"a day later", at(5, 10, 12, 0), at(5, 3, 12, 0), at(5, 4, 12, 0), at(5, 11, 12, 0)},
+		{"a day earlier across a month", at(6, 1, 12, 0), at(5, 3, 12, 0), at(5, 2, 19, 0), at(5, 31, 19, 0)},
+		{"over the end of daylight saving", at(11, 8, 12, 0), at(11, 1, 12, 0), at(11, 1, 18, 0), at(11, 8, 18, 0)},
+		{"over the start of daylight saving", at(3, 15, 12, 0), at(3, 8, 12, 0), at(3, 9, 12, 0), at(3, 16, 12, 0)},
+		{"from another time zone", at(5, 10, 12, 0), at(5, 3, 12, 0), time.Date(2024, 5, 3, 22, 0, 0, 0, time.UTC), at(5, 10, 18, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shiftWallClock(tt.t, tt.from, tt.to, newYork); !got.Equal(tt.want) {
+				t.Errorf("shiftWallClock() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
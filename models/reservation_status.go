@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReservationStatus string
+
+const (
+	StatusPending   ReservationStatus = "pending"
+	StatusConfirmed ReservationStatus = "confirmed"
+	StatusSeated    ReservationStatus = "seated"
+	StatusCompleted ReservationStatus = "completed"
+	StatusCancelled ReservationStatus = "cancelled"
+	StatusNoShow    ReservationStatus = "no_show"
+)
+
+var ReservationStatuses = []ReservationStatus{StatusPending, StatusConfirmed, StatusSeated, StatusCompleted, StatusCancelled, StatusNoShow}
+
+// Reservations in these statuses still hold their table
+var ActiveStatuses = []ReservationStatus{StatusPending, StatusConfirmed, StatusSeated}
+
+// pending -> confirmed -> seated -> completed, and a booking can be cancelled or marked
+// as a no-show until the guest is seated
+var reservationTransitions = map[ReservationStatus][]ReservationStatus{
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusSeated, StatusCancelled, StatusNoShow},
+	StatusSeated:    {StatusCompleted},
+}
+
+func (s ReservationStatus) IsActive() bool {
+	for _, status := range ActiveStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (s ReservationStatus) IsValid() bool {
+	for _, status := range ReservationStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (s ReservationStatus) CanTransitionTo(to ReservationStatus) bool {
+	for _, status := range reservationTransitions[s] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+type InvalidTransitionError struct {
+	From ReservationStatus
+	To   ReservationStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("a %s reservation can't be changed to %s", e.From, e.To)
+}
+
+// Every status change of a reservation, with who did it and when
+type ReservationStatusChange struct {
+	ID            uint              `gorm:"primaryKey"`
+	ReservationID uint              `json:"reservationId" gorm:"index"`
+	FromStatus    ReservationStatus `json:"fromStatus"`
+	ToStatus      ReservationStatus `json:"toStatus"`
+	ChangedByID   uint              `json:"changedById"`
+	ChangedAt     time.Time         `json:"changedAt"`
+	gorm.Model    `json:"-" swaggerignore:"true"`
+}
+
+// Move the reservation to a new status if the state machine allows it, and record the change.
+// Returns an *InvalidTransitionError otherwise.
+func (h *ReservationHandler) TransitionReservation(id uint, to ReservationStatus, actorID uint) (*Reservation, error) {
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		return transitionReservation(tx, id, to, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.GetReservation(id)
+}
+
+func transitionReservation(tx *gorm.DB, id uint, to ReservationStatus, actorID uint) error {
+	var reservation Reservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id).Error; err != nil {
+		return err
+	}
+
+	if !reservation.Status.CanTransitionTo(to) {
+		return &InvalidTransitionError{From: reservation.Status, To: to}
+	}
+
+	// Guests have to cancel before the restaurant's deadline, staff can cancel any time
+	if to == StatusCancelled && actorID == reservation.UserID {
+		if err := checkCancellationWindow(tx, &reservation); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	result := tx.Model(&Reservation{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":               to,
+		"status_changed_at":    now,
+		"status_changed_by_id": actorID,
+		"sequence":             gorm.Expr("sequence + ?", 1),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	err := tx.Create(&ReservationStatusChange{
+		ReservationID: id,
+		FromStatus:    reservation.Status,
+		ToStatus:      to,
+		ChangedByID:   actorID,
+		ChangedAt:     now,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	changed := reservation
+	changed.Status = to
+	if err := recordHistory(tx, id, HistoryStatusChanged, actorID, &reservation, &changed); err != nil {
+		return err
+	}
+
+	switch to {
+	case StatusCancelled:
+		// A cancelled booking frees its table for the waitlist
+		return offerFreedSlot(tx, reservation.RestaurantID, reservation.DateTime, reservation.ExitTime)
+	case StatusNoShow:
+		return tx.Model(&User{}).Where("id = ?", reservation.UserID).
+			UpdateColumn("no_show_count", gorm.Expr("no_show_count + ?", 1)).Error
+	}
+	return nil
+}
+
+func (h *ReservationHandler) GetStatusChanges(reservationID uint) ([]ReservationStatusChange, error) {
+	var changes []ReservationStatusChange
+	result := h.db.Where("reservation_id = ?", reservationID).Order("changed_at").Find(&changes)
+	return changes, result.Error
+}
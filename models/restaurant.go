@@ -2,24 +2,31 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Restaurant struct {
-	ID           uint     `gorm:"primaryKey"`
-	Name         string   `json:"name"`
-	Address      string   `json:"address"`
-	Telephone    string   `json:"telephone"`
-	OpenTime     string   `json:"openTime"`
-	CloseTime    string   `json:"closeTime"`
-	Instagram    string   `json:"instagram"`
-	Facebook     string   `json:"facebook"`
-	Description  string   `json:"description"`
-	Rating       *float64 `json:"rating" gorm:"default:0" validate:"required,min=0"`
-	CommentCount *float64 `json:"commentCount" gorm:"default:0" validate:"required,min=0"`
-	ImageURL     string   `json:"imageUrl"`
-	gorm.Model   `json:"-" swaggerignore:"true"`
+	ID            uint               `gorm:"primaryKey"`
+	Name          string             `json:"name"`
+	Address       string             `json:"address"`
+	Telephone     string             `json:"telephone"`
+	OpenTime      string             `json:"openTime"`
+	CloseTime     string             `json:"closeTime"`
+	Instagram     string             `json:"instagram"`
+	Facebook      string             `json:"facebook"`
+	Description   string             `json:"description"`
+	Rating        *float64           `json:"rating" gorm:"default:0" validate:"required,min=0"`
+	CommentCount  *float64           `json:"commentCount" gorm:"default:0" validate:"required,min=0"`
+	ImageURL      string             `json:"imageUrl"`
+	TimeZone      string             `json:"timeZone" example:"Asia/Bangkok"`
+	OpenNow       bool               `json:"openNow" gorm:"-"`
+	Schedule      []OpeningInterval  `json:"schedule" gorm:"foreignKey:RestaurantID"`
+	Exceptions    []OpeningException `json:"exceptions" gorm:"foreignKey:RestaurantID"`
+	CalendarToken string             `json:"-"`
+	gorm.Model    `json:"-" swaggerignore:"true"`
 }
 
 type RestaurantHandler struct {
@@ -34,20 +41,49 @@ func (h *RestaurantHandler) CreateRestaurant(restaurant *Restaurant) error {
 	return h.db.Create(restaurant).Error
 }
 
+// Load the weekly schedule and the exceptions that aren't over yet
+func withOpeningHours(db *gorm.DB) *gorm.DB {
+	since := time.Now().AddDate(0, 0, -2).Format("2006-01-02")
+	return db.
+		Preload("Schedule", func(db *gorm.DB) *gorm.DB { return db.Order("weekday, open_time") }).
+		Preload("Exceptions", func(db *gorm.DB) *gorm.DB { return db.Where("date >= ?", since).Order("date, open_time") })
+}
+
+func (r *Restaurant) setOpenNow() {
+	if r.Schedule == nil {
+		r.Schedule = []OpeningInterval{}
+	}
+	if r.Exceptions == nil {
+		r.Exceptions = []OpeningException{}
+	}
+	r.OpenNow = r.IsOpenAt(time.Now())
+}
+
 func (h *RestaurantHandler) GetRestaurant(id uint) (*Restaurant, error) {
 	var restaurant Restaurant
-	result := h.db.First(&restaurant, id)
+	result := withOpeningHours(h.db).First(&restaurant, id)
+	restaurant.setOpenNow()
 	return &restaurant, result.Error
 }
 
 func (h *RestaurantHandler) GetRestaurants() ([]Restaurant, error) {
 	var restaurants []Restaurant
-	result := h.db.Find(&restaurants)
+	result := withOpeningHours(h.db).Find(&restaurants)
+	for i := range restaurants {
+		restaurants[i].setOpenNow()
+	}
 	return restaurants, result.Error
 }
 
+// The schedule and exceptions have their own endpoints, so they are never saved from here
 func (h *RestaurantHandler) UpdateRestaurant(id uint, restaurant *Restaurant) error {
-	result := h.db.Model(&Restaurant{}).Where("id = ?", id).Updates(restaurant)
+	result := h.db.Model(&Restaurant{}).Where("id = ?", id).Omit(clause.Associations).Updates(restaurant)
+	return result.Error
+}
+
+// Store the hash of the secret token for the restaurant's calendar feed
+func (h *RestaurantHandler) SetCalendarToken(id uint, tokenHash string) error {
+	result := h.db.Model(&Restaurant{}).Where("id = ?", id).Update("calendar_token", tokenHash)
 	return result.Error
 }
 
@@ -0,0 +1,24 @@
+package models
+
+const (
+	RoleUser            = "user"
+	RoleRestaurantStaff = "restaurant_staff"
+	RoleRestaurantOwner = "restaurant_owner"
+	RoleAdmin           = "admin"
+)
+
+var Roles = []string{RoleUser, RoleRestaurantStaff, RoleRestaurantOwner, RoleAdmin}
+
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Restaurant roles only make sense together with a restaurant
+func IsRestaurantRole(role string) bool {
+	return role == RoleRestaurantStaff || role == RoleRestaurantOwner
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/punchanabu/redrice-backend-go/utils"
+	"gorm.io/gorm"
+)
+
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+type Session struct {
+	ID                uint       `gorm:"primaryKey"`
+	UserID            uint       `json:"userId" gorm:"index"`
+	RefreshTokenHash  string     `json:"-" gorm:"uniqueIndex"`
+	PreviousTokenHash string     `json:"-" gorm:"index"`
+	ExpiresAt         time.Time  `json:"expiresAt"`
+	MFAVerified       bool       `json:"mfaVerified"`
+	RevokedAt         *time.Time `json:"revokedAt"`
+	gorm.Model        `json:"-" swaggerignore:"true"`
+}
+
+type SessionHandler struct {
+	db *gorm.DB
+}
+
+func NewSessionHandler(db *gorm.DB) *SessionHandler {
+	return &SessionHandler{db}
+}
+
+// Create a new session for the user and return the refresh token that belongs to it
+func (h *SessionHandler) CreateSession(userID uint, mfaVerified bool) (*Session, string, error) {
+	refreshToken, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	session := Session{
+		UserID:           userID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(RefreshTokenTTL),
+		MFAVerified:      mfaVerified,
+	}
+
+	if err := h.db.Create(&session).Error; err != nil {
+		return nil, "", err
+	}
+
+	return &session, refreshToken, nil
+}
+
+// Exchange a refresh token for a new one. The old token stops working right away,
+// and presenting it again revokes the whole session since it was most likely stolen.
+func (h *SessionHandler) RotateSession(refreshToken string) (*Session, string, error) {
+	hash := utils.HashToken(refreshToken)
+
+	var session Session
+	if err := h.db.Where("refresh_token_hash = ?", hash).First(&session).Error; err != nil {
+		var reused Session
+		if h.db.Where("previous_token_hash = ?", hash).First(&reused).Error == nil {
+			h.RevokeSession(reused.ID)
+		}
+		return nil, "", fmt.Errorf("invalid refresh token")
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, "", fmt.Errorf("session has expired")
+	}
+
+	newToken, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := h.db.Model(&Session{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, hash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  utils.HashToken(newToken),
+			"previous_token_hash": hash,
+			"expires_at":          time.Now().Add(RefreshTokenTTL),
+		})
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, "", fmt.Errorf("invalid refresh token")
+	}
+
+	return &session, newToken, nil
+}
+
+func (h *SessionHandler) GetSession(id uint) (*Session, error) {
+	var session Session
+	result := h.db.First(&session, id)
+	return &session, result.Error
+}
+
+func (h *SessionHandler) MarkMFAVerified(id uint) error {
+	result := h.db.Model(&Session{}).Where("id = ?", id).Update("mfa_verified", true)
+	return result.Error
+}
+
+func (h *SessionHandler) IsSessionActive(id uint) bool {
+	var session Session
+	if err := h.db.First(&session, id).Error; err != nil {
+		return false
+	}
+	return session.RevokedAt == nil && time.Now().Before(session.ExpiresAt)
+}
+
+func (h *SessionHandler) RevokeSession(id uint) error {
+	result := h.db.Model(&Session{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now())
+	return result.Error
+}
+
+func (h *SessionHandler) RevokeUserSessions(userID uint) error {
+	result := h.db.Model(&Session{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", time.Now())
+	return result.Error
+}
+
+// Revoke every session of the user except the one making the request
+func (h *SessionHandler) RevokeOtherSessions(userID uint, keepID uint) error {
+	result := h.db.Model(&Session{}).Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).Update("revoked_at", time.Now())
+	return result.Error
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotateSession(t *testing.T) {
+	db := testDB(t)
+	h := NewSessionHandler(db)
+
+	tests := []struct {
+		name string
+		// Refresh tokens to present in order, by how many rotations ago they were handed out
+		present []int
+		wantOK  []bool
+		active  bool
+	}{
+		{"rotating the latest token works every time", []int{0, 0, 0}, []bool{true, true, true}, true},
+		{"reusing a rotated token revokes the session", []int{0, 1}, []bool{true, false}, false},
+		{"after a reuse the latest token stops working too", []int{0, 1, 0}, []bool{true, false, false}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, token, err := h.CreateSession(1, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokens := []string{token}
+
+			for i, ago := range tt.present {
+				_, next, err := h.RotateSession(tokens[len(tokens)-1-ago])
+				if ok := err == nil; ok != tt.wantOK[i] {
+					t.Fatalf("rotation %d: err = %v, want ok %v", i, err, tt.wantOK[i])
+				}
+				if err == nil {
+					tokens = append(tokens, next)
+				}
+			}
+
+			if active := h.IsSessionActive(session.ID); active != tt.active {
+				t.Errorf("session active = %v, want %v", active, tt.active)
+			}
+		})
+	}
+
+	t.Run("unknown token", func(t *testing.T) {
+		if _, _, err := h.RotateSession("not-a-token"); err == nil {
+			t.Error("RotateSession() accepted an unknown token")
+		}
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		session, token, err := h.CreateSession(1, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Model(&Session{}).Where("id = ?", session.ID).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := h.RotateSession(token); err == nil {
+			t.Error("RotateSession() rotated an expired session")
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTableExists              = errors.New("a table with this number already exists")
+	ErrTableInUse               = errors.New("the table still has upcoming reservations, move or cancel them first")
+	ErrTableTooSmallForBookings = errors.New("upcoming reservations on the table have more guests than the new seat count, move them first")
+)
+
+type Table struct {
+	ID           uint `gorm:"primaryKey"`
+	RestaurantID uint `json:"restaurantId" gorm:"index;uniqueIndex:idx_restaurant_table_number,where:deleted_at IS NULL"`
+	Number       int  `json:"number" gorm:"uniqueIndex:idx_restaurant_table_number,where:deleted_at IS NULL"`
+	Seats        int  `json:"seats"`
+	gorm.Model   `json:"-" swaggerignore:"true"`
+}
+
+type TableHandler struct {
+	db *gorm.DB
+}
+
+func NewTableHandler(db *gorm.DB) *TableHandler {
+	return &TableHandler{db}
+}
+
+// The unique index decides, so two requests for the same number can't both get it
+func tableError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrTableExists
+	}
+	return err
+}
+
+func (h *TableHandler) CreateTable(table *Table) error {
+	return tableError(h.db.Create(table).Error)
+}
+
+func (h *TableHandler) GetTable(id uint) (*Table, error) {
+	var table Table
+	result := h.db.First(&table, id)
+	return &table, result.Error
+}
+
+func (h *TableHandler) GetTablesByRestaurantID(restaurantID uint) ([]Table, error) {
+	var tables []Table
+	result := h.db.Where("restaurant_id = ?", restaurantID).Order("number").Find(&tables)
+	return tables, result.Error
+}
+
+// Bookings on the table keep its new number, and its seats can't drop below a party that is
+// booked or seated on it
+func (h *TableHandler) UpdateTable(id uint, table *Table) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		var current Table
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, id).Error; err != nil {
+			return err
+		}
+
+		if table.Seats != 0 && table.Seats < current.Seats {
+			var reservations, walkIns, holds int64
+			if err := tx.Model(&Reservation{}).
+				Where("table_id = ? AND status IN ? AND exit_time > ? AND party_size > ?", id, ActiveStatuses, time.Now(), table.Seats).
+				Count(&reservations).Error; err != nil {
+				return err
+			}
+			if err := seatedWalkIns(tx).Where("table_id = ? AND party_size > ?", id, table.Seats).Count(&walkIns).Error; err != nil {
+				return err
+			}
+			if err := activeHolds(tx).Where("table_id = ? AND party_size > ?", id, table.Seats).Count(&holds).Error; err != nil {
+				return err
+			}
+			if reservations > 0 || walkIns > 0 || holds > 0 {
+				return ErrTableTooSmallForBookings
+			}
+		}
+
+		if err := tx.Model(&Table{}).Where("id = ?", id).Updates(table).Error; err != nil {
+			return tableError(err)
+		}
+
+		if table.Number == 0 || table.Number == current.Number {
+			return nil
+		}
+		// Calendars pick the new number up with the next sequence
+		if err := tx.Model(&Reservation{}).Where("table_id = ?", id).Updates(map[string]interface{}{
+			"table_num": table.Number,
+			"sequence":  gorm.Expr("sequence + ?", 1),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&WalkIn{}).Where("table_id = ?", id).Update("table_num", table.Number).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&WaitlistEntry{}).Where("table_id = ?", id).Update("table_num", table.Number).Error; err != nil {
+			return err
+		}
+		return tx.Model(&ReservationSeries{}).
+			Where("restaurant_id = ? AND table_num = ?", current.RestaurantID, current.Number).
+			Update("table_num", table.Number).Error
+	})
+}
+
+// Tables that upcoming reservations or seated walk-ins are using can't be deleted
+func (h *TableHandler) DeleteTable(id uint) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		// Bookings lock the tables of the restaurant before checking for overlaps, so none can sneak in
+		var table Table
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&table, id).Error; err != nil {
+			return err
+		}
+
+		var reservations, walkIns int64
+		if err := tx.Model(&Reservation{}).
+			Where("table_id = ? AND status IN ? AND exit_time > ?", id, ActiveStatuses, time.Now()).
+			Count(&reservations).Error; err != nil {
+			return err
+		}
+		if err := seatedWalkIns(tx).Where("table_id = ?", id).Count(&walkIns).Error; err != nil {
+			return err
+		}
+		if reservations > 0 || walkIns > 0 {
+			return ErrTableInUse
+		}
+
+		return tx.Delete(&Table{}, id).Error
+	})
+}
@@ -2,20 +2,37 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+const MinPasswordLength = 8
+
+// Compared against on unknown emails, so they take as long as a wrong password
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("redrice-dummy-password"), bcrypt.DefaultCost)
+
 type User struct {
-	ID           uint   `gorm:"primaryKey"`
-	Name         string `json:"name"`
-	Email        string `json:"email" gorm:"unique"`
-	Telephone    string `json:"telephone" gorm:"unique"`
-	Role         string `json:"role"`
-	Password     string `json:"password"`
-	RestaurantId uint   `json:"restaurant_id"`
-	gorm.Model   `json:"-" swaggerignore:"true"`
+	ID              uint       `gorm:"primaryKey"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email" gorm:"unique"`
+	Telephone       string     `json:"telephone" gorm:"unique"`
+	Role            string     `json:"role"`
+	Password        string     `json:"-"`
+	RestaurantId    uint       `json:"restaurant_id"`
+	AvatarURL       string     `json:"avatarUrl"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	TOTPSecret      string     `json:"-"`
+	TOTPEnabledAt   *time.Time `json:"totpEnabledAt"`
+	TOTPLastStep    int64      `json:"-"`
+	NoShowCount     int        `json:"noShowCount" gorm:"default:0"`
+	CalendarToken   string     `json:"-"`
+	gorm.Model      `json:"-" swaggerignore:"true"`
+}
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 type UserHandler struct {
@@ -39,6 +56,10 @@ func (h UserHandler) CreateUser(user *User) error {
 		return fmt.Errorf("telephone already exists")
 	}
 
+	if user.Role == "" {
+		user.Role = RoleUser
+	}
+
 	// Hash the password before storing
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -59,6 +80,87 @@ func (h *UserHandler) CheckPassword(email, password string) bool {
 	return err == nil
 }
 
+// Re-hash the new password with bcrypt and store it
+func (h *UserHandler) UpdatePassword(id uint, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	result := h.db.Model(&User{}).Where("id = ?", id).Update("password", string(hashedPassword))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no user found with id %d", id)
+	}
+	return nil
+}
+
+func (u *User) IsMFAEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// Store a new TOTP secret, it only takes effect once EnableTOTP is called
+func (h *UserHandler) SetTOTPSecret(id uint, secret string) error {
+	result := h.db.Model(&User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"totp_secret":     secret,
+		"totp_enabled_at": nil,
+		"totp_last_step":  0,
+	})
+	return result.Error
+}
+
+func (h *UserHandler) EnableTOTP(id uint) error {
+	result := h.db.Model(&User{}).Where("id = ? AND totp_secret <> ''", id).Update("totp_enabled_at", time.Now())
+	return result.Error
+}
+
+func (h *UserHandler) DisableTOTP(id uint) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", id).Delete(&RecoveryCode{}).Error
+	})
+}
+
+// Remember the time step of an accepted code, so the same code can't be replayed
+func (h *UserHandler) UseTOTPStep(id uint, step int64) bool {
+	result := h.db.Model(&User{}).Where("id = ? AND totp_last_step < ?", id, step).Update("totp_last_step", step)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// Store the hash of the secret token for the calendar feed, the old feed url stops working
+func (h *UserHandler) SetCalendarToken(id uint, tokenHash string) error {
+	result := h.db.Model(&User{}).Where("id = ?", id).Update("calendar_token", tokenHash)
+	return result.Error
+}
+
+func (h *UserHandler) MarkEmailVerified(id uint) error {
+	result := h.db.Model(&User{}).Where("id = ? AND email_verified_at IS NULL", id).Update("email_verified_at", time.Now())
+	return result.Error
+}
+
+// Look up the user and check the password without revealing which of the two was wrong
+func (h *UserHandler) Authenticate(email, password string) (*User, bool) {
+	user, err := h.GetUserByEmail(email)
+	if err != nil {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, false
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, false
+	}
+	return user, true
+}
+
 func (h *UserHandler) GetUser(id uint) (*User, error) {
 	var user User
 	result := h.db.First(&user, id)
@@ -76,6 +178,23 @@ func (h *UserHandler) UpdateUser(id uint, user *User) error {
 	return result.Error
 }
 
+// Update only the given columns, so fields can also be cleared
+func (h *UserHandler) UpdateUserFields(id uint, fields map[string]interface{}) error {
+	result := h.db.Model(&User{}).Where("id = ?", id).Updates(fields)
+	return result.Error
+}
+
+// Update the columns of a user whose email changes. Links mailed to the old address stop working,
+// otherwise a verification token for it would verify the new one.
+func (h *UserHandler) UpdateUserEmailFields(id uint, fields map[string]interface{}) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND used_at IS NULL", id).Delete(&UserToken{}).Error
+	})
+}
+
 func (h *UserHandler) DeleteUser(id uint) error {
 	result := h.db.Delete(&User{}, id)
 	return result.Error
@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestUseTOTPStep(t *testing.T) {
+	db := testDB(t)
+	h := NewUserHandler(db)
+
+	user := User{Name: "Somchai", Email: "totp@example.com", Telephone: "0800000001", Role: RoleUser}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Steps in the order the codes are used
+	tests := []struct {
+		name string
+		step int64
+		want bool
+	}{
+		{"first code", 1000, true},
+		{"the same code again", 1000, false},
+		{"an older code", 999, false},
+		{"the next code", 1001, true},
+		{"skipping ahead", 1005, true},
+		{"a code from the window before it", 1004, false},
+	}
+
+	for _, tt := range tests {
+		if got := h.UseTOTPStep(user.ID, tt.step); got != tt.want {
+			t.Errorf("%s: UseTOTPStep(%d) = %v, want %v", tt.name, tt.step, got, tt.want)
+		}
+	}
+}
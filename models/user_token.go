@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/punchanabu/redrice-backend-go/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+const (
+	PasswordResetTokenTTL     = time.Hour
+	EmailVerificationTokenTTL = 24 * time.Hour
+)
+
+// Single-use token that is mailed to a user, only the hash is stored
+type UserToken struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `json:"userId" gorm:"index"`
+	Purpose    string     `json:"purpose" gorm:"index"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	UsedAt     *time.Time `json:"usedAt"`
+	gorm.Model `json:"-" swaggerignore:"true"`
+}
+
+type UserTokenHandler struct {
+	db *gorm.DB
+}
+
+func NewUserTokenHandler(db *gorm.DB) *UserTokenHandler {
+	return &UserTokenHandler{db}
+}
+
+// Create a token for the given purpose, any older unused token with the same purpose stops working
+func (h *UserTokenHandler) CreateToken(userID uint, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).Delete(&UserToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+
+	return token, err
+}
+
+// Mark the token as used and return it. A token can only be consumed once.
+func (h *UserTokenHandler) ConsumeToken(token string, purpose string) (*UserToken, error) {
+	hash := utils.HashToken(token)
+	now := time.Now()
+
+	result := h.db.Model(&UserToken{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("token is invalid or expired")
+	}
+
+	var userToken UserToken
+	if err := h.db.Where("token_hash = ?", hash).First(&userToken).Error; err != nil {
+		return nil, err
+	}
+
+	return &userToken, nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// What any logged in user may see about someone else
+type PublicUser struct {
+	ID        uint   `json:"ID"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatarUrl"`
+}
+
+// What users see about themselves
+type OwnerUser struct {
+	ID              uint       `json:"ID"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Telephone       string     `json:"telephone"`
+	Role            string     `json:"role"`
+	RestaurantId    uint       `json:"restaurant_id"`
+	AvatarURL       string     `json:"avatarUrl"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	TOTPEnabledAt   *time.Time `json:"totpEnabledAt"`
+	NoShowCount     int        `json:"noShowCount"`
+}
+
+// What admins see about any user
+type AdminUser struct {
+	OwnerUser
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (u *User) PublicView() PublicUser {
+	return PublicUser{
+		ID:        u.ID,
+		Name:      u.Name,
+		AvatarURL: u.AvatarURL,
+	}
+}
+
+func (u *User) OwnerView() OwnerUser {
+	return OwnerUser{
+		ID:              u.ID,
+		Name:            u.Name,
+		Email:           u.Email,
+		Telephone:       u.Telephone,
+		Role:            u.Role,
+		RestaurantId:    u.RestaurantId,
+		AvatarURL:       u.AvatarURL,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		TOTPEnabledAt:   u.TOTPEnabledAt,
+		NoShowCount:     u.NoShowCount,
+	}
+}
+
+func (u *User) AdminView() AdminUser {
+	return AdminUser{
+		OwnerUser: u.OwnerView(),
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// Problems with single fields of a request, keyed by the json name of the field
+type ValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *ValidationError) Add(field string, message string) {
+	if e.Fields == nil {
+		e.Fields = map[string]string{}
+	}
+	if _, exists := e.Fields[field]; !exists {
+		e.Fields[field] = message
+	}
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field+" "+e.Fields[field])
+	}
+	return strings.Join(messages, ", ")
+}
+
+// nil when nothing was added, so it can be returned as an error directly
+func (e *ValidationError) OrNil() error {
+	if e == nil || len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
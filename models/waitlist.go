@@ -0,0 +1,373 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting WaitlistStatus = "waiting"
+	WaitlistOffered WaitlistStatus = "offered"
+	WaitlistBooked  WaitlistStatus = "booked"
+	WaitlistExpired WaitlistStatus = "expired"
+	WaitlistLeft    WaitlistStatus = "left"
+)
+
+var (
+	ErrNoOffer           = errors.New("there is no open offer for this waitlist entry")
+	ErrOfferExpired      = errors.New("the offer has expired")
+	ErrNotWaiting        = errors.New("waitlist entry is no longer waiting")
+	ErrTableFree         = errors.New("a table is free for this time, book it instead")
+	ErrAlreadyOnWaitlist = errors.New("already on the waitlist of this restaurant for an overlapping time")
+)
+
+// A guest waiting for a table between DateTime and ExitTime. When a booking that overlaps
+// is cancelled, the entry gets a table held for it until HoldExpiresAt.
+type WaitlistEntry struct {
+	ID            uint           `gorm:"primaryKey"`
+	RestaurantID  uint           `json:"restaurantId" gorm:"index"`
+	Restaurant    Restaurant     `gorm:"foreignKey:RestaurantID" json:"restaurant"`
+	UserID        uint           `json:"userId" gorm:"index"`
+	User          User           `gorm:"foreignKey:UserID" json:"user"`
+	DateTime      time.Time      `json:"dateTime"`
+	ExitTime      time.Time      `json:"exitTime"`
+	PartySize     int            `json:"partySize"`
+	Status        WaitlistStatus `json:"status" gorm:"default:waiting;index"`
+	TableID       uint           `json:"tableId"`
+	TableNum      int            `json:"tableNum"`
+	HoldExpiresAt *time.Time     `json:"holdExpiresAt"`
+	ReservationID *uint          `json:"reservationId"`
+	NotifiedAt    *time.Time     `json:"-"`
+	Position      int            `json:"position,omitempty" gorm:"-"`
+	gorm.Model    `json:"-" swaggerignore:"true"`
+}
+
+// Nested users only show their public profile
+func (e WaitlistEntry) MarshalJSON() ([]byte, error) {
+	type waitlistEntry WaitlistEntry
+	return json.Marshal(struct {
+		waitlistEntry
+		User PublicUser `json:"user"`
+	}{waitlistEntry(e), e.User.PublicView()})
+}
+
+// How long an offered table is kept, WAITLIST_HOLD_MINUTES or 15 minutes
+func waitlistHoldDuration() time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv("WAITLIST_HOLD_MINUTES")); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return 15 * time.Minute
+}
+
+// Offers whose hold is still running, they keep their table like a booking does
+func activeHolds(db *gorm.DB) *gorm.DB {
+	return db.Model(&WaitlistEntry{}).Where("status = ? AND hold_expires_at > ?", WaitlistOffered, time.Now())
+}
+
+// The held table as a reservation, so it can be checked for overlaps like one
+func (e *WaitlistEntry) heldSlot() *Reservation {
+	return &Reservation{
+		RestaurantID: e.RestaurantID,
+		TableID:      e.TableID,
+		TableNum:     e.TableNum,
+		DateTime:     e.DateTime,
+		ExitTime:     e.ExitTime,
+		PartySize:    e.PartySize,
+		Status:       StatusPending,
+	}
+}
+
+// Offer freed up time at the restaurant to the first waiting entry whose window overlaps it
+// and that now fits on a table. Must run inside a transaction.
+func offerFreedSlot(tx *gorm.DB, restaurantID uint, from time.Time, to time.Time) error {
+	var entries []WaitlistEntry
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("restaurant_id = ? AND status = ? AND date_time < ? AND exit_time > ? AND date_time > ?", restaurantID, WaitlistWaiting, to, from, time.Now()).
+		Order("id").
+		Find(&entries)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	for _, entry := range entries {
+		slot := entry.heldSlot()
+		slot.TableNum = 0
+
+		err := assignTable(tx, slot, entry.PartySize, 0)
+		var conflict *ReservationConflictError
+		var tooSmall *ValidationError
+		if errors.As(err, &conflict) || errors.As(err, &tooSmall) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		holdExpiresAt := time.Now().Add(waitlistHoldDuration())
+		return tx.Model(&WaitlistEntry{}).Where("id = ?", entry.ID).Updates(map[string]interface{}{
+			"status":          WaitlistOffered,
+			"table_id":        slot.TableID,
+			"table_num":       slot.TableNum,
+			"hold_expires_at": holdExpiresAt,
+			"notified_at":     nil,
+		}).Error
+	}
+
+	return nil
+}
+
+type WaitlistHandler struct {
+	db *gorm.DB
+}
+
+func NewWaitlistHandler(db *gorm.DB) *WaitlistHandler {
+	return &WaitlistHandler{db}
+}
+
+func (h *WaitlistHandler) JoinWaitlist(userID uint, entry *WaitlistEntry) error {
+	entry.UserID = userID
+	entry.Status = WaitlistWaiting
+	entry.TableID = 0
+	entry.TableNum = 0
+	entry.HoldExpiresAt = nil
+	entry.ReservationID = nil
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		slot := entry.heldSlot()
+		if err := validateSlot(tx, slot, true); err != nil {
+			return err
+		}
+
+		// Only fully booked times have a waitlist. This also locks the restaurant's tables,
+		// so the same guest can't join twice at once.
+		err := assignTable(tx, slot, entry.PartySize, 0)
+		var conflict *ReservationConflictError
+		var tooSmall *ValidationError
+		switch {
+		case err == nil:
+			return ErrTableFree
+		case !errors.As(err, &conflict) && !errors.As(err, &tooSmall):
+			return err
+		}
+
+		var waiting int64
+		result := tx.Model(&WaitlistEntry{}).
+			Where("restaurant_id = ? AND user_id = ? AND status IN ? AND date_time < ? AND exit_time > ?", entry.RestaurantID, userID, []WaitlistStatus{WaitlistWaiting, WaitlistOffered}, entry.ExitTime, entry.DateTime).
+			Count(&waiting)
+		if result.Error != nil {
+			return result.Error
+		}
+		if waiting > 0 {
+			return ErrAlreadyOnWaitlist
+		}
+
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.withPosition(entry)
+}
+
+func (h *WaitlistHandler) GetEntry(id uint) (*WaitlistEntry, error) {
+	var entry WaitlistEntry
+	if err := h.db.Preload("User").Preload("Restaurant").First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, h.withPosition(&entry)
+}
+
+// Entries of the user that are still waiting or have an offer, with their place in the queue
+func (h *WaitlistHandler) GetEntriesByUserID(userID uint) ([]WaitlistEntry, error) {
+	var entries []WaitlistEntry
+	result := h.db.Preload("User").Preload("Restaurant").
+		Where("user_id = ? AND status IN ?", userID, []WaitlistStatus{WaitlistWaiting, WaitlistOffered}).
+		Order("date_time").
+		Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for i := range entries {
+		if err := h.withPosition(&entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// The queue of the restaurant in the order offers are made
+func (h *WaitlistHandler) GetEntriesByRestaurantID(restaurantID uint) ([]WaitlistEntry, error) {
+	var entries []WaitlistEntry
+	result := h.db.Preload("User").Preload("Restaurant").
+		Where("restaurant_id = ? AND status IN ?", restaurantID, []WaitlistStatus{WaitlistWaiting, WaitlistOffered}).
+		Order("id").
+		Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for i := range entries {
+		if err := h.withPosition(&entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// Position is 1 plus the waiting entries at the same restaurant that joined earlier and
+// want an overlapping time, since those get offered a freed table first
+func (h *WaitlistHandler) withPosition(entry *WaitlistEntry) error {
+	if entry.Status != WaitlistWaiting {
+		entry.Position = 0
+		return nil
+	}
+
+	var ahead int64
+	result := h.db.Model(&WaitlistEntry{}).
+		Where("restaurant_id = ? AND status = ? AND id < ? AND date_time < ? AND exit_time > ?", entry.RestaurantID, WaitlistWaiting, entry.ID, entry.ExitTime, entry.DateTime).
+		Count(&ahead)
+	entry.Position = int(ahead) + 1
+	return result.Error
+}
+
+// Turn the offer into a pending reservation on the held table, enforceLimit works like in CreateReservation
+func (h *WaitlistHandler) ConfirmOffer(id uint, enforceLimit bool) (*Reservation, error) {
+	var reservation Reservation
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var entry WaitlistEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, id).Error; err != nil {
+			return err
+		}
+		if entry.Status != WaitlistOffered {
+			return ErrNoOffer
+		}
+		if entry.HoldExpiresAt == nil || entry.HoldExpiresAt.Before(time.Now()) {
+			return ErrOfferExpired
+		}
+		reservation = Reservation{
+			UserID:       entry.UserID,
+			RestaurantID: entry.RestaurantID,
+			DateTime:     entry.DateTime,
+			ExitTime:     entry.ExitTime,
+			TableNum:     entry.TableNum,
+			PartySize:    entry.PartySize,
+			Status:       StatusPending,
+		}
+		// Opening hours or the booking policy may have changed since the guest joined
+		if err := validateSlot(tx, &reservation, true); err != nil {
+			return err
+		}
+		if enforceLimit {
+			if err := enforceBookingLimit(tx, entry.UserID, entry.RestaurantID); err != nil {
+				return err
+			}
+		}
+
+		// Mark it booked first so its own hold doesn't block the table
+		if err := tx.Model(&WaitlistEntry{}).Where("id = ?", id).Update("status", WaitlistBooked).Error; err != nil {
+			return err
+		}
+
+		if err := assignTable(tx, &reservation, entry.PartySize, 0); err != nil {
+			return err
+		}
+		if err := tx.Create(&reservation).Error; err != nil {
+			return err
+		}
+		if err := recordHistory(tx, reservation.ID, HistoryCreated, entry.UserID, nil, &reservation); err != nil {
+			return err
+		}
+
+		return tx.Model(&WaitlistEntry{}).Where("id = ?", id).Update("reservation_id", reservation.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.db.Preload("User").Preload("Restaurant").First(&reservation, reservation.ID).Error; err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// Take the entry off the waitlist, a table that was held for it goes to the next in line
+func (h *WaitlistHandler) LeaveWaitlist(id uint) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		var entry WaitlistEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, id).Error; err != nil {
+			return err
+		}
+		if entry.Status != WaitlistWaiting && entry.Status != WaitlistOffered {
+			return ErrNotWaiting
+		}
+
+		if err := tx.Model(&WaitlistEntry{}).Where("id = ?", id).Update("status", WaitlistLeft).Error; err != nil {
+			return err
+		}
+
+		if entry.Status == WaitlistOffered {
+			return offerFreedSlot(tx, entry.RestaurantID, entry.DateTime, entry.ExitTime)
+		}
+		return nil
+	})
+}
+
+// Offers whose guest hasn't been told about them yet
+func (h *WaitlistHandler) GetUnnotifiedOffers() ([]WaitlistEntry, error) {
+	var entries []WaitlistEntry
+	result := activeHolds(h.db).Preload("User").Preload("Restaurant").
+		Where("notified_at IS NULL").
+		Order("id").
+		Find(&entries)
+	return entries, result.Error
+}
+
+func (h *WaitlistHandler) MarkNotified(id uint) error {
+	return h.db.Model(&WaitlistEntry{}).Where("id = ?", id).Update("notified_at", time.Now()).Error
+}
+
+// Expire offers that weren't confirmed in time and pass their tables on. Returns how many expired.
+func (h *WaitlistHandler) ExpireOffers() (int, error) {
+	var expired []WaitlistEntry
+	result := h.db.Where("status = ? AND hold_expires_at <= ?", WaitlistOffered, time.Now()).Find(&expired)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	count := 0
+	for _, entry := range expired {
+		changed := false
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			// Only expire it if it wasn't confirmed in the meantime
+			update := tx.Model(&WaitlistEntry{}).
+				Where("id = ? AND status = ?", entry.ID, WaitlistOffered).
+				Update("status", WaitlistExpired)
+			if update.Error != nil || update.RowsAffected == 0 {
+				return update.Error
+			}
+
+			changed = true
+			return offerFreedSlot(tx, entry.RestaurantID, entry.DateTime, entry.ExitTime)
+		})
+		if err != nil {
+			return count, err
+		}
+		// Only counted once it is committed
+		if changed {
+			count++
+		}
+	}
+
+	return count, nil
+}
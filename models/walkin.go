@@ -0,0 +1,471 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WalkInStatus string
+
+const (
+	WalkInWaiting  WalkInStatus = "waiting"
+	WalkInCalled   WalkInStatus = "called"
+	WalkInSeated   WalkInStatus = "seated"
+	WalkInFinished WalkInStatus = "finished"
+	WalkInSkipped  WalkInStatus = "skipped"
+	WalkInLeft     WalkInStatus = "left"
+)
+
+// Walk-ins in these statuses are still in the queue
+var QueuedWalkInStatuses = []WalkInStatus{WalkInWaiting, WalkInCalled}
+
+var walkInTransitions = map[WalkInStatus][]WalkInStatus{
+	WalkInWaiting: {WalkInCalled, WalkInSeated, WalkInSkipped, WalkInLeft},
+	WalkInCalled:  {WalkInSeated, WalkInSkipped, WalkInLeft},
+	WalkInSeated:  {WalkInFinished},
+}
+
+var (
+	ErrAlreadyQueued  = errors.New("already in the queue of this restaurant")
+	ErrNoFittingTable = errors.New("restaurant has no table for a party this size")
+)
+
+type InvalidWalkInTransitionError struct {
+	From WalkInStatus
+	To   WalkInStatus
+}
+
+func (e *InvalidWalkInTransitionError) Error() string {
+	return "walk-in can't go from " + string(e.From) + " to " + string(e.To)
+}
+
+func (s WalkInStatus) IsQueued() bool {
+	return s == WalkInWaiting || s == WalkInCalled
+}
+
+func (s WalkInStatus) CanTransitionTo(to WalkInStatus) bool {
+	for _, status := range walkInTransitions[s] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// A guest in the queue for the next free table. Guests can join from their phone, staff can add
+// guests at the door without an account. Once seated the walk-in keeps its table until LeaveAt,
+// or until it is marked finished, and reservations can't be put on it in the meantime.
+type WalkIn struct {
+	ID                   uint         `gorm:"primaryKey"`
+	RestaurantID         uint         `json:"restaurantId" gorm:"index"`
+	Restaurant           Restaurant   `gorm:"foreignKey:RestaurantID" json:"-"`
+	UserID               *uint        `json:"userId" gorm:"index"`
+	User                 *User        `gorm:"foreignKey:UserID" json:"-"`
+	Name                 string       `json:"name" example:"Somchai"`
+	PartySize            int          `json:"partySize" example:"2"`
+	QueueDate            string       `json:"queueDate" example:"2024-05-01"`
+	TicketNumber         int          `json:"ticketNumber" example:"12"`
+	Status               WalkInStatus `json:"status" gorm:"default:waiting;index"`
+	CalledAt             *time.Time   `json:"calledAt"`
+	SeatedAt             *time.Time   `json:"seatedAt"`
+	LeaveAt              *time.Time   `json:"leaveAt"`
+	FinishedAt           *time.Time   `json:"finishedAt"`
+	TableID              uint         `json:"tableId"`
+	TableNum             int          `json:"tableNum"`
+	Position             int          `json:"position,omitempty" gorm:"-"`
+	EstimatedWaitMinutes *int         `json:"estimatedWaitMinutes,omitempty" gorm:"-"`
+	gorm.Model           `json:"-" swaggerignore:"true"`
+}
+
+// Staff see the name of the guest's account when they joined from their phone
+func (w WalkIn) MarshalJSON() ([]byte, error) {
+	type walkIn WalkIn
+	if w.Name == "" && w.User != nil {
+		w.Name = w.User.Name
+	}
+	return json.Marshal(walkIn(w))
+}
+
+// Seated walk-ins that still hold their table
+func seatedWalkIns(db *gorm.DB) *gorm.DB {
+	return db.Model(&WalkIn{}).Where("status = ? AND leave_at > ?", WalkInSeated, time.Now())
+}
+
+// The table of a seated walk-in as a reservation, so it can be checked for overlaps like one
+func (w *WalkIn) occupiedSlot() *Reservation {
+	return &Reservation{
+		RestaurantID: w.RestaurantID,
+		TableID:      w.TableID,
+		TableNum:     w.TableNum,
+		DateTime:     *w.SeatedAt,
+		ExitTime:     *w.LeaveAt,
+		PartySize:    w.PartySize,
+		Status:       StatusSeated,
+	}
+}
+
+type WalkInHandler struct {
+	db *gorm.DB
+}
+
+func NewWalkInHandler(db *gorm.DB) *WalkInHandler {
+	return &WalkInHandler{db}
+}
+
+// Put the walk-in at the end of today's queue. Tickets start at 1 every day in the restaurant's time zone.
+func (h *WalkInHandler) JoinQueue(walkIn *WalkIn) error {
+	walkIn.Status = WalkInWaiting
+	walkIn.CalledAt = nil
+	walkIn.SeatedAt = nil
+	walkIn.LeaveAt = nil
+	walkIn.FinishedAt = nil
+	walkIn.TableID = 0
+	walkIn.TableNum = 0
+
+	problems := &ValidationError{}
+	if walkIn.PartySize <= 0 {
+		problems.Add("partySize", "must be above 0")
+	}
+	if walkIn.UserID == nil && walkIn.Name == "" {
+		problems.Add("name", "is required for guests without an account")
+	}
+	if err := problems.OrNil(); err != nil {
+		return err
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the restaurant so two guests can't draw the same ticket
+		var restaurant Restaurant
+		err := withOpeningHours(tx).Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&restaurant, walkIn.RestaurantID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ValidationError{Fields: map[string]string{"restaurantId": "does not exist"}}
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if restaurant.HasOpeningHours() && !restaurant.IsOpenAt(now) {
+			return &ValidationError{Fields: map[string]string{"restaurantId": "is closed right now"}}
+		}
+
+		var largest int
+		if err := tx.Model(&Table{}).Where("restaurant_id = ?", restaurant.ID).Select("COALESCE(MAX(seats), 0)").Scan(&largest).Error; err != nil {
+			return err
+		}
+		if largest < walkIn.PartySize {
+			return ErrNoFittingTable
+		}
+
+		walkIn.QueueDate = now.In(restaurant.Location()).Format("2006-01-02")
+
+		if walkIn.UserID != nil {
+			var queued int64
+			result := tx.Model(&WalkIn{}).
+				Where("restaurant_id = ? AND queue_date = ? AND user_id = ? AND status IN ?", restaurant.ID, walkIn.QueueDate, *walkIn.UserID, QueuedWalkInStatuses).
+				Count(&queued)
+			if result.Error != nil {
+				return result.Error
+			}
+			if queued > 0 {
+				return ErrAlreadyQueued
+			}
+		}
+
+		var last int
+		result := tx.Model(&WalkIn{}).Where("restaurant_id = ? AND queue_date = ?", restaurant.ID, walkIn.QueueDate).
+			Select("COALESCE(MAX(ticket_number), 0)").Scan(&last)
+		if result.Error != nil {
+			return result.Error
+		}
+		walkIn.TicketNumber = last + 1
+
+		return tx.Create(walkIn).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.withEstimate(walkIn)
+}
+
+func (h *WalkInHandler) GetWalkIn(id uint) (*WalkIn, error) {
+	var walkIn WalkIn
+	if err := h.db.Preload("User").First(&walkIn, id).Error; err != nil {
+		return nil, err
+	}
+	return &walkIn, h.withEstimate(&walkIn)
+}
+
+// Today's walk-ins at the restaurant that are queued or still seated, in ticket order.
+// Whoever was left in the queue on an earlier day isn't coming anymore.
+func (h *WalkInHandler) GetQueue(restaurant *Restaurant) ([]WalkIn, error) {
+	now := time.Now()
+	today := now.In(restaurant.Location()).Format("2006-01-02")
+
+	var walkIns []WalkIn
+	result := h.db.Preload("User").
+		Where("restaurant_id = ? AND ((queue_date = ? AND status IN ?) OR (status = ? AND leave_at > ?))", restaurant.ID, today, QueuedWalkInStatuses, WalkInSeated, now).
+		Order("queue_date, ticket_number").
+		Find(&walkIns)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// The queue is already in ticket order, so it's walked once
+	estimator, err := h.newQueueEstimator(restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+	position := 0
+	for i := range walkIns {
+		walkIns[i].Position = 0
+		walkIns[i].EstimatedWaitMinutes = nil
+		if walkIns[i].Status.IsQueued() {
+			position++
+			estimator.next(&walkIns[i], position)
+		}
+	}
+	return walkIns, nil
+}
+
+// Queued walk-ins of the user at any restaurant from the last day, older queues are over
+func (h *WalkInHandler) GetWalkInsByUserID(userID uint) ([]WalkIn, error) {
+	since := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+
+	var walkIns []WalkIn
+	result := h.db.Preload("User").Where("user_id = ? AND status IN ? AND queue_date >= ?", userID, QueuedWalkInStatuses, since).Order("id").Find(&walkIns)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for i := range walkIns {
+		if err := h.withEstimate(&walkIns[i]); err != nil {
+			return nil, err
+		}
+	}
+	return walkIns, nil
+}
+
+// How long a table is usually taken, from the walk-ins and reservations that finished lately.
+// Falls back to DefaultDiningDuration for restaurants without any history yet.
+func (h *WalkInHandler) turnover(restaurantID uint) (time.Duration, error) {
+	var total time.Duration
+	count := 0
+
+	var finished []WalkIn
+	result := h.db.Where("restaurant_id = ? AND status = ? AND seated_at IS NOT NULL AND finished_at IS NOT NULL", restaurantID, WalkInFinished).
+		Order("finished_at DESC").Limit(50).Find(&finished)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	for _, walkIn := range finished {
+		total += walkIn.FinishedAt.Sub(*walkIn.SeatedAt)
+		count++
+	}
+
+	var completed []ReservationStatusChange
+	result = h.db.Joins("JOIN reservations ON reservations.id = reservation_status_changes.reservation_id").
+		Where("reservations.restaurant_id = ? AND reservation_status_changes.to_status = ?", restaurantID, StatusCompleted).
+		Order("reservation_status_changes.changed_at DESC").Limit(50).Find(&completed)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if len(completed) > 0 {
+		ids := make([]uint, len(completed))
+		for i, change := range completed {
+			ids[i] = change.ReservationID
+		}
+
+		var seated []ReservationStatusChange
+		if err := h.db.Where("reservation_id IN ? AND to_status = ?", ids, StatusSeated).Find(&seated).Error; err != nil {
+			return 0, err
+		}
+		seatedAt := map[uint]time.Time{}
+		for _, change := range seated {
+			seatedAt[change.ReservationID] = change.ChangedAt
+		}
+		for _, change := range completed {
+			if at, ok := seatedAt[change.ReservationID]; ok && change.ChangedAt.After(at) {
+				total += change.ChangedAt.Sub(at)
+				count++
+			}
+		}
+	}
+
+	if count == 0 {
+		return DefaultDiningDuration, nil
+	}
+	return total / time.Duration(count), nil
+}
+
+// Earliest time from from on that the table is free for length, given what's already on it
+func nextFreeAt(taken []Reservation, tableID uint, from time.Time, length time.Duration) time.Time {
+	var busy []Reservation
+	for _, slot := range taken {
+		if slot.TableID == tableID {
+			busy = append(busy, slot)
+		}
+	}
+	sort.Slice(busy, func(i, j int) bool { return busy[i].DateTime.Before(busy[j].DateTime) })
+
+	at := from
+	for _, slot := range busy {
+		if !slot.ExitTime.After(at) {
+			continue
+		}
+		if !slot.DateTime.Before(at.Add(length)) {
+			break
+		}
+		at = slot.ExitTime
+	}
+	return at
+}
+
+// Hands out the tables of a restaurant to queued walk-ins in ticket order, to estimate their wait.
+// Every walk-in keeps a table for the usual turnover, around the bookings and seated guests already on them.
+type queueEstimator struct {
+	now      time.Time
+	turnover time.Duration
+	tables   []Table
+	taken    []Reservation
+}
+
+// Load what the estimate needs once, so a whole queue can be walked without more queries
+func (h *WalkInHandler) newQueueEstimator(restaurantID uint) (*queueEstimator, error) {
+	turnover, err := h.turnover(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []Table
+	if err := h.db.Where("restaurant_id = ?", restaurantID).Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	taken, err := (&TableHandler{h.db}).takenSlots(restaurantID, now, now.Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	return &queueEstimator{now: now, turnover: turnover, tables: tables, taken: taken}, nil
+}
+
+// Seat a party on the table that frees up first, and keep it there for the turnover
+func (e *queueEstimator) seat(partySize int) (time.Time, bool) {
+	best := -1
+	var bestAt time.Time
+	for i, table := range e.tables {
+		if table.Seats < partySize {
+			continue
+		}
+		at := nextFreeAt(e.taken, table.ID, e.now, e.turnover)
+		if best == -1 || at.Before(bestAt) {
+			best, bestAt = i, at
+		}
+	}
+	if best == -1 {
+		return time.Time{}, false
+	}
+	e.taken = append(e.taken, Reservation{TableID: e.tables[best].ID, DateTime: bestAt, ExitTime: bestAt.Add(e.turnover)})
+	return bestAt, true
+}
+
+// Give the next walk-in in ticket order its position and estimated wait
+func (e *queueEstimator) next(walkIn *WalkIn, position int) {
+	walkIn.Position = position
+	walkIn.EstimatedWaitMinutes = nil
+	if at, ok := e.seat(walkIn.PartySize); ok {
+		minutes := int(at.Sub(e.now).Round(time.Minute) / time.Minute)
+		walkIn.EstimatedWaitMinutes = &minutes
+	}
+}
+
+// Position is 1 plus the walk-ins ahead in today's queue, the wait is estimated by seating
+// everyone ahead first
+func (h *WalkInHandler) withEstimate(walkIn *WalkIn) error {
+	walkIn.Position = 0
+	walkIn.EstimatedWaitMinutes = nil
+	if !walkIn.Status.IsQueued() {
+		return nil
+	}
+
+	var ahead []WalkIn
+	result := h.db.Where("restaurant_id = ? AND queue_date = ? AND status IN ? AND ticket_number < ?", walkIn.RestaurantID, walkIn.QueueDate, QueuedWalkInStatuses, walkIn.TicketNumber).
+		Order("ticket_number").
+		Find(&ahead)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	estimator, err := h.newQueueEstimator(walkIn.RestaurantID)
+	if err != nil {
+		return err
+	}
+	for i := range ahead {
+		estimator.next(&ahead[i], i+1)
+	}
+	estimator.next(walkIn, len(ahead)+1)
+	return nil
+}
+
+// Move the walk-in along the queue. Seating puts it on the requested table, or the smallest free one
+// when tableNum is 0, and only on a table that has no booking for the next DefaultDiningDuration.
+// Finishing frees the table, and the waitlist gets offered the time that was left.
+func (h *WalkInHandler) TransitionWalkIn(id uint, to WalkInStatus, tableNum int) (*WalkIn, error) {
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var walkIn WalkIn
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&walkIn, id).Error; err != nil {
+			return err
+		}
+		if !walkIn.Status.CanTransitionTo(to) {
+			return &InvalidWalkInTransitionError{From: walkIn.Status, To: to}
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{"status": to}
+
+		switch to {
+		case WalkInCalled:
+			updates["called_at"] = now
+		case WalkInSeated:
+			slot := &Reservation{
+				RestaurantID: walkIn.RestaurantID,
+				TableNum:     tableNum,
+				DateTime:     now,
+				ExitTime:     now.Add(DefaultDiningDuration),
+			}
+			if err := assignTable(tx, slot, walkIn.PartySize, 0); err != nil {
+				return err
+			}
+			updates["seated_at"] = now
+			updates["leave_at"] = slot.ExitTime
+			updates["table_id"] = slot.TableID
+			updates["table_num"] = slot.TableNum
+		case WalkInFinished:
+			updates["finished_at"] = now
+			updates["leave_at"] = now
+		}
+
+		if err := tx.Model(&WalkIn{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if to == WalkInFinished && walkIn.LeaveAt != nil && walkIn.LeaveAt.After(now) {
+			return offerFreedSlot(tx, walkIn.RestaurantID, now, *walkIn.LeaveAt)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.GetWalkIn(id)
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextFreeAt(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2024, 5, 1, hour, minute, 0, 0, time.UTC)
+	}
+	slot := func(tableID uint, from time.Time, to time.Time) Reservation {
+		return Reservation{TableID: tableID, DateTime: from, ExitTime: to}
+	}
+
+	tests := []struct {
+		name   string
+		taken  []Reservation
+		length time.Duration
+		want   time.Time
+	}{
+		{
+			name:   "empty table",
+			length: time.Hour,
+			want:   now,
+		},
+		{
+			name:   "other tables don't count",
+			taken:  []Reservation{slot(2, at(11, 0), at(14, 0))},
+			length: time.Hour,
+			want:   now,
+		},
+		{
+			name:   "seated until later",
+			taken:  []Reservation{slot(1, at(11, 0), at(13, 0))},
+			length: time.Hour,
+			want:   at(13, 0),
+		},
+		{
+			name:   "fits before the next booking",
+			taken:  []Reservation{slot(1, at(14, 0), at(16, 0))},
+			length: 2 * time.Hour,
+			want:   now,
+		},
+		{
+			name:   "too short a gap before the next booking",
+			taken:  []Reservation{slot(1, at(13, 30), at(15, 0))},
+			length: 2 * time.Hour,
+			want:   at(15, 0),
+		},
+		{
+			name: "skips gaps that are too short, out of order",
+			taken: []Reservation{
+				slot(1, at(16, 0), at(17, 0)),
+				slot(1, at(11, 30), at(12, 30)),
+				slot(1, at(13, 0), at(15, 0)),
+			},
+			length: time.Hour,
+			want:   at(15, 0),
+		},
+		{
+			name: "finished bookings are ignored",
+			taken: []Reservation{
+				slot(1, at(9, 0), at(11, 0)),
+				slot(1, at(10, 0), at(12, 0)),
+			},
+			length: time.Hour,
+			want:   now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextFreeAt(tt.taken, 1, now, tt.length); !got.Equal(tt.want) {
+				t.Errorf("nextFreeAt() = %s, want %s", got.Format("15:04"), tt.want.Format("15:04"))
+			}
+		})
+	}
+}
+
+func TestQueueEstimator(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	estimator := &queueEstimator{
+		now:      now,
+		turnover: time.Hour,
+		tables:   []Table{{ID: 1, Seats: 2}, {ID: 2, Seats: 4}},
+		taken:    []Reservation{{TableID: 2, DateTime: now.Add(-time.Hour), ExitTime: now.Add(30 * time.Minute)}},
+	}
+
+	queue := []WalkIn{{PartySize: 2}, {PartySize: 2}, {PartySize: 4}, {PartySize: 8}}
+	want := []*int{intPtr(0), intPtr(30), intPtr(90), nil}
+	for i := range queue {
+		estimator.next(&queue[i], i+1)
+
+		if queue[i].Position != i+1 {
+			t.Errorf("walk-in %d has position %d", i, queue[i].Position)
+		}
+		got := queue[i].EstimatedWaitMinutes
+		if (got == nil) != (want[i] == nil) || (got != nil && *got != *want[i]) {
+			t.Errorf("walk-in %d waits %v minutes, want %v", i, deref(got), deref(want[i]))
+		}
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func deref(i *int) interface{} {
+	if i == nil {
+		return nil
+	}
+	return *i
+}
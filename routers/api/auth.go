@@ -1,37 +1,130 @@
 package api
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/punchanabu/redrice-backend-go/middleware"
 	"github.com/punchanabu/redrice-backend-go/models"
+	"github.com/punchanabu/redrice-backend-go/utils"
 	"gorm.io/gorm"
 )
 
 var userHandler *models.UserHandler
-var restaurantHandler *models.RestaurantHandler
+var sessionHandler *models.SessionHandler
+var userTokenHandler *models.UserTokenHandler
+var recoveryCodeHandler *models.RecoveryCodeHandler
+var loginAttemptHandler *models.LoginAttemptHandler
+var mailer utils.Mailer
+
+// Failed logins are limited per ip and per account, swap these out for a shared store when running more than one server
+var ipLimiter middleware.LoginLimiter = middleware.NewMemoryLimiter(20, 15*time.Minute, 15*time.Minute)
+var accountLimiter middleware.LoginLimiter = middleware.NewMemoryLimiter(5, 15*time.Minute, 15*time.Minute)
 
 func InitializedAuthHandler(db *gorm.DB) {
 	userHandler = models.NewUserHandler(db)
-	restaurantHandler = models.NewRestaurantHandler(db)
+	sessionHandler = models.NewSessionHandler(db)
+	userTokenHandler = models.NewUserTokenHandler(db)
+	recoveryCodeHandler = models.NewRecoveryCodeHandler(db)
+	loginAttemptHandler = models.NewLoginAttemptHandler(db)
+}
+
+// Set the mail sender, e.g. the one from utils.NewMailerFromEnv or one that captures mails in tests
+func SetMailer(m utils.Mailer) {
+	mailer = m
+}
+
+// Replace the login limiters, e.g. with ones backed by a shared store
+func SetLoginLimiters(ip middleware.LoginLimiter, account middleware.LoginLimiter) {
+	ipLimiter = ip
+	accountLimiter = account
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// How long the ip or the account still has to wait before it may try to log in again
+func loginLockedFor(email string, ip string) time.Duration {
+	wait := ipLimiter.LockedFor(ip)
+	if accountWait := accountLimiter.LockedFor(normalizeEmail(email)); accountWait > wait {
+		wait = accountWait
+	}
+	return wait
 }
 
+func recordLoginFailure(email string, ip string) {
+	ipLimiter.RecordFailure(ip)
+	accountLimiter.RecordFailure(normalizeEmail(email))
+	if err := loginAttemptHandler.RecordAttempt(normalizeEmail(email), ip, false); err != nil {
+		log.Println("Error recording login attempt:", err)
+	}
+}
+
+func recordLoginSuccess(email string, ip string) {
+	email = normalizeEmail(email)
+	accountLimiter.Reset(email)
+	// The stored failures of the account go with its limiter, the ip keeps its own
+	if err := loginAttemptHandler.ClearFailedAttempts(email, ""); err != nil {
+		log.Println("Error clearing login attempts:", err)
+	}
+	if err := loginAttemptHandler.RecordAttempt(email, ip, true); err != nil {
+		log.Println("Error recording login attempt:", err)
+	}
+}
+
+func tooManyAttempts(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, please try again later"})
+}
+
+type TokenResponse struct {
+	Token        string `json:"token" example:""`
+	RefreshToken string `json:"refreshToken" example:""`
+	ExpiresIn    int    `json:"expiresIn" example:"900"`
+}
+
+// Start a new session for the user and hand back an access token with its refresh token
+func issueTokens(user *models.User, mfaVerified bool) (*TokenResponse, error) {
+	session, refreshToken, err := sessionHandler.CreateSession(user.ID, mfaVerified)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := middleware.GenerateToken(user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(middleware.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Fields a visitor may set when signing up, role and restaurant are only assigned by admins
 type RegisterDetails struct {
-	Name         string `json:"name" example:"John Doe"`
-	Telephone    string `json:"telephone" example:"123-456-7890"`
-	Email        string `json:"email" example:"john.doe@example.com"`
-	Password     string `json:"password" example:"securePassword123"`
-	Role         string `json:"role" example:"user"`
-	RestaurantId uint   `json:"restaurant_id" example:"0"`
+	Name      string `json:"name" example:"John Doe"`
+	Telephone string `json:"telephone" example:"123-456-7890"`
+	Email     string `json:"email" example:"john.doe@example.com"`
+	Password  string `json:"password" example:"securePassword123"`
 }
 
 type RegisterResponse struct {
-	Message string `json:"message" example:"User registered successfully"`
+	Message      string `json:"message" example:"User registered successfully, please check your email to verify your account"`
+	Token        string `json:"token" example:""`
+	RefreshToken string `json:"refreshToken" example:""`
+	ExpiresIn    int    `json:"expiresIn" example:"900"`
 }
 
 // @Summary Register a new user
-// @Description Creates a new user account with the provided details. Upon successful creation, the user can log in with their credentials.
+// @Description Creates a new user account with the provided details and sends a verification link to the email. The user can log in right away, but can't make reservations or comments until the email is verified.
 // @Tags authentication
 // @Accept json
 // @Produce json
@@ -41,20 +134,24 @@ type RegisterResponse struct {
 // @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
 // @Router /auth/register [post]
 func Register(c *gin.Context) {
-	var newUser models.User
+	var details RegisterDetails
 
-	if err := c.ShouldBindJSON(&newUser); err != nil {
+	if err := c.ShouldBindJSON(&details); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
 		return
 	}
 
-	// Check if the restaurant exists
-	if newUser.RestaurantId != 0 {
-		restaurant, err := restaurantHandler.GetRestaurant(newUser.RestaurantId)
-		if err != nil || restaurant == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant ID"})
-			return
-		}
+	if len(details.Password) < models.MinPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", models.MinPasswordLength)})
+		return
+	}
+
+	newUser := models.User{
+		Name:      details.Name,
+		Telephone: details.Telephone,
+		Email:     details.Email,
+		Password:  details.Password,
+		Role:      models.RoleUser,
 	}
 
 	err := userHandler.CreateUser(&newUser)
@@ -63,7 +160,11 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	token, err := middleware.GenerateToken(newUser.Email, newUser.ID, newUser.Role)
+	if err := sendVerificationEmail(&newUser); err != nil {
+		log.Println("Error sending verification mail:", err)
+	}
+
+	tokens, err := issueTokens(&newUser, false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
 		return
@@ -72,8 +173,10 @@ func Register(c *gin.Context) {
 	c.JSON(
 		http.StatusOK,
 		gin.H{
-			"message": "User registered successfully",
-			"token":   token,
+			"message":      "User registered successfully, please check your email to verify your account",
+			"token":        tokens.Token,
+			"refreshToken": tokens.RefreshToken,
+			"expiresIn":    tokens.ExpiresIn,
 		},
 	)
 }
@@ -84,8 +187,12 @@ type LoginDetails struct {
 }
 
 type LoginResponse struct {
-	Token   string `json:"token" example:""`
-	Message string `json:"message" example:"Login successful"`
+	Token        string `json:"token,omitempty" example:""`
+	RefreshToken string `json:"refreshToken,omitempty" example:""`
+	ExpiresIn    int    `json:"expiresIn,omitempty" example:"900"`
+	MFARequired  bool   `json:"mfaRequired,omitempty" example:"false"`
+	MFAToken     string `json:"mfaToken,omitempty" example:""`
+	Message      string `json:"message" example:"Login successful"`
 }
 
 type ErrorResponse struct {
@@ -94,7 +201,8 @@ type ErrorResponse struct {
 
 // Login a user
 // @Summary User Login
-// @Description Authenticates a user by their email and password, returning a JWT token for authorized access to protected endpoints if successful.
+// @Description Authenticates a user by their email and password, returning a short-lived JWT token for authorized access to protected endpoints and a refresh token to renew it.
+// @Description If the account has two-factor authentication enabled, an MFA challenge token is returned instead, which must be exchanged at /auth/mfa/verify.
 // @Tags authentication
 // @Accept json
 // @Produce json
@@ -102,7 +210,7 @@ type ErrorResponse struct {
 // @Success 200 {object} LoginResponse "An object containing a JWT token for authentication and a message indicating successful login."
 // @Failure 400 {object} ErrorResponse "The request was formatted incorrectly or missing required fields."
 // @Failure 401 {object} ErrorResponse "Authentication failed due to invalid login credentials."
-// @Failure 404 {object} ErrorResponse "The specified user was not found in the system."
+// @Failure 429 {object} ErrorResponse "Too many failed attempts from this ip or for this account, try again later."
 // @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
 // @Router /auth/signin [post]
 func Login(c *gin.Context) {
@@ -113,24 +221,44 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	user, err := userHandler.GetUserByEmail(loginDetails.Email)
-
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	ip := c.ClientIP()
+	if wait := loginLockedFor(loginDetails.Email, ip); wait > 0 {
+		tooManyAttempts(c, wait)
 		return
 	}
 
-	if user == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Authentication failed"})
+	// Same answer for unknown emails and wrong passwords, so accounts can't be enumerated
+	user, ok := userHandler.Authenticate(loginDetails.Email, loginDetails.Password)
+	if !ok {
+		recordLoginFailure(loginDetails.Email, ip)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
-	if !userHandler.CheckPassword(user.Email, loginDetails.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect!"})
+	// Accounts with two-factor enabled get a challenge instead of a session. The failures of the
+	// account are only forgotten once the second factor is verified too, so knowing the password
+	// doesn't allow to guess codes past the limit.
+	if user.IsMFAEnabled() {
+		mfaToken, err := middleware.GenerateMFAToken(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+			return
+		}
+
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"mfaRequired": true,
+				"mfaToken":    mfaToken,
+				"message":     "Enter the code from your authenticator app",
+			},
+		)
 		return
 	}
 
-	token, err := middleware.GenerateToken(user.Email, user.ID, user.Role)
+	recordLoginSuccess(loginDetails.Email, ip)
+
+	tokens, err := issueTokens(user, false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
 		return
@@ -139,8 +267,110 @@ func Login(c *gin.Context) {
 	c.JSON(
 		http.StatusOK,
 		gin.H{
-			"token":   token,
-			"message": "Login successful",
+			"token":        tokens.Token,
+			"refreshToken": tokens.RefreshToken,
+			"expiresIn":    tokens.ExpiresIn,
+			"message":      "Login successful",
 		},
 	)
 }
+
+type RefreshDetails struct {
+	RefreshToken string `json:"refreshToken" example:""`
+}
+
+// @Summary Refresh Access Token
+// @Description Exchanges a refresh token for a new access token. The refresh token is rotated, so the one sent in the request can't be used again.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param body body RefreshDetails true "Refresh Token"
+// @Success 200 {object} TokenResponse "A new access token and refresh token."
+// @Failure 400 {object} ErrorResponse "The request was formatted incorrectly or missing required fields."
+// @Failure 401 {object} ErrorResponse "The refresh token is invalid, expired or revoked."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/refresh [post]
+func Refresh(c *gin.Context) {
+	var details RefreshDetails
+	if err := c.ShouldBindJSON(&details); err != nil || details.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
+		return
+	}
+
+	session, refreshToken, err := sessionHandler.RotateSession(details.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token is invalid or expired 🥹 Please login again!"})
+		return
+	}
+
+	// Reload the user so role changes are picked up on refresh
+	user, err := userHandler.GetUser(session.UserID)
+	if err != nil {
+		sessionHandler.RevokeSession(session.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	token, err := middleware.GenerateToken(user, session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(middleware.AccessTokenTTL.Seconds()),
+	})
+}
+
+type MessageResponse struct {
+	Message string `json:"message" example:"Logged out successfully"`
+}
+
+// @Summary Logout
+// @Description Revokes the current session. The access token and refresh token of this session stop working immediately.
+// @Tags authentication
+// @Produce json
+// @security BearerAuth
+// @Success 200 {object} MessageResponse "Confirmation of successful logout."
+// @Failure 401 {object} ErrorResponse "Unauthorized."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/logout [post]
+func Logout(c *gin.Context) {
+	sessionId, _ := c.Get("sessionId")
+
+	if err := sessionHandler.RevokeSession(sessionId.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error revoking session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// @Summary Logout From All Devices
+// @Description Revokes every session of the current user, including the one making the request.
+// @Tags authentication
+// @Produce json
+// @security BearerAuth
+// @Success 200 {object} MessageResponse "Confirmation of successful logout."
+// @Failure 401 {object} ErrorResponse "Unauthorized."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/logout-all [post]
+func LogoutAll(c *gin.Context) {
+	id, _ := c.Get("id")
+
+	if err := sessionHandler.RevokeUserSessions(id.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error revoking sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out from all devices successfully"})
+}
+
+// Public keys RedRice tokens are signed with, so other services can verify them
+// without sharing a secret. Served at /.well-known/jwks.json outside of the api prefix.
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, middleware.PublicJWKS())
+}
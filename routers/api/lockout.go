@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+type LockoutsResponse struct {
+	Accounts       []middleware.Lockout  `json:"accounts"`
+	IPs            []middleware.Lockout  `json:"ips"`
+	FailedAttempts []models.LoginAttempt `json:"failedAttempts"`
+}
+
+type ClearLockoutDetails struct {
+	Email string `json:"email" example:"user@example.com"`
+	IP    string `json:"ip" example:"203.0.113.7"`
+}
+
+// @Summary Get Login Lockouts
+// @Description Lists the accounts and ips that are currently locked out after too many failed logins, together with the failed attempts of the last 24 hours.
+// @Tags authentication
+// @Produce json
+// @security BearerAuth
+// @Success 200 {object} LockoutsResponse "Current lockouts and recent failed attempts."
+// @Failure 403 {object} ErrorResponse "Only admins can see lockouts."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /lockouts [get]
+func GetLockouts(c *gin.Context) {
+	attempts, err := loginAttemptHandler.GetFailedAttempts(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching login attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LockoutsResponse{
+		Accounts:       accountLimiter.Lockouts(),
+		IPs:            ipLimiter.Lockouts(),
+		FailedAttempts: attempts,
+	})
+}
+
+// @Summary Clear a Login Lockout
+// @Description Lets an account or an ip log in again right away and forgets its failed attempts. The failures of the account from every ip and of the ip for every account are forgotten.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param body body ClearLockoutDetails true "Account Email and/or IP"
+// @security BearerAuth
+// @Success 200 {object} MessageResponse "The lockout has been cleared."
+// @Failure 400 {object} ErrorResponse "Neither an email nor an ip was given."
+// @Failure 403 {object} ErrorResponse "Only admins can clear lockouts."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /lockouts [delete]
+func ClearLockout(c *gin.Context) {
+	var details ClearLockoutDetails
+	if err := c.ShouldBindJSON(&details); err != nil || (details.Email == "" && details.IP == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please give an email or an ip to clear"})
+		return
+	}
+
+	email := normalizeEmail(details.Email)
+	if email != "" {
+		accountLimiter.Reset(email)
+	}
+	if details.IP != "" {
+		ipLimiter.Reset(details.IP)
+	}
+
+	if err := loginAttemptHandler.ClearFailedAttempts(email, details.IP); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error clearing login attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lockout cleared successfully"})
+}
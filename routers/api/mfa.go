@@ -0,0 +1,267 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"github.com/punchanabu/redrice-backend-go/utils"
+)
+
+const totpIssuer = "RedRice"
+
+type MFAVerifyDetails struct {
+	MFAToken     string `json:"mfaToken" example:""`
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recoveryCode" example:""`
+}
+
+type MFACodeDetails struct {
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recoveryCode" example:""`
+}
+
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OtpauthURL string `json:"otpauthUrl" example:"otpauth://totp/RedRice:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=RedRice"`
+}
+
+type MFAConfirmResponse struct {
+	Message       string   `json:"message" example:"Two-factor authentication enabled"`
+	RecoveryCodes []string `json:"recoveryCodes" example:"abcd-efgh"`
+	Token         string   `json:"token" example:""`
+}
+
+// Check a TOTP code, or a recovery code if no TOTP code was given
+func verifySecondFactor(user *models.User, code string, recoveryCode string) bool {
+	if code != "" {
+		step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+		return ok && userHandler.UseTOTPStep(user.ID, step)
+	}
+	if recoveryCode != "" {
+		return recoveryCodeHandler.UseCode(user.ID, recoveryCode)
+	}
+	return false
+}
+
+// @Summary Verify MFA Challenge
+// @Description Exchanges the MFA challenge token from /auth/signin together with a TOTP code (or a recovery code) for an access token and refresh token.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param body body MFAVerifyDetails true "MFA Challenge and Code"
+// @Success 200 {object} TokenResponse "An access token and refresh token."
+// @Failure 400 {object} ErrorResponse "The request was formatted incorrectly or missing required fields."
+// @Failure 401 {object} ErrorResponse "The challenge token or the code is invalid."
+// @Failure 429 {object} ErrorResponse "Too many failed attempts, try again later."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/mfa/verify [post]
+func VerifyMFA(c *gin.Context) {
+	var details MFAVerifyDetails
+	if err := c.ShouldBindJSON(&details); err != nil || details.MFAToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
+		return
+	}
+
+	claims, err := middleware.ValidateMFAToken(details.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "MFA challenge is invalid or expired 🥹 Please login again!"})
+		return
+	}
+
+	user, err := userHandler.GetUser(claims.UserId)
+	if err != nil || !user.IsMFAEnabled() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "MFA challenge is invalid or expired 🥹 Please login again!"})
+		return
+	}
+
+	ip := c.ClientIP()
+	if wait := loginLockedFor(user.Email, ip); wait > 0 {
+		tooManyAttempts(c, wait)
+		return
+	}
+
+	if !verifySecondFactor(user, details.Code, details.RecoveryCode) {
+		recordLoginFailure(user.Email, ip)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	recordLoginSuccess(user.Email, ip)
+
+	tokens, err := issueTokens(user, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Start MFA Enrollment
+// @Description Generates a new TOTP secret for the current user. Add it to an authenticator app and call /auth/mfa/confirm with a code to turn two-factor authentication on.
+// @Tags authentication
+// @Produce json
+// @security BearerAuth
+// @Success 200 {object} MFAEnrollResponse "The TOTP secret and an otpauth:// url for QR codes."
+// @Failure 400 {object} ErrorResponse "Two-factor authentication is already enabled."
+// @Failure 401 {object} ErrorResponse "Unauthorized."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/mfa/enroll [post]
+func EnrollMFA(c *gin.Context) {
+	id, _ := c.Get("id")
+
+	user, err := userHandler.GetUser(id.(uint))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.IsMFAEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating secret"})
+		return
+	}
+
+	if err := userHandler.SetTOTPSecret(user.ID, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MFAEnrollResponse{
+		Secret:     secret,
+		OtpauthURL: utils.TOTPProvisioningURI(secret, user.Email, totpIssuer),
+	})
+}
+
+// @Summary Confirm MFA Enrollment
+// @Description Turns two-factor authentication on after checking a code from the authenticator app. Returns one-time recovery codes, which are only shown once, and a new access token for the current session.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param body body MFACodeDetails true "Code from the Authenticator App"
+// @security BearerAuth
+// @Success 200 {object} MFAConfirmResponse "Recovery codes and a new access token."
+// @Failure 400 {object} ErrorResponse "Enrollment was not started, is already done, or the code is invalid."
+// @Failure 401 {object} ErrorResponse "Unauthorized."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/mfa/confirm [post]
+func ConfirmMFA(c *gin.Context) {
+	var details MFACodeDetails
+	if err := c.ShouldBindJSON(&details); err != nil || details.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
+		return
+	}
+
+	id, _ := c.Get("id")
+	sessionId, _ := c.Get("sessionId")
+
+	user, err := userHandler.GetUser(id.(uint))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.IsMFAEnabled() || user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please start the enrollment at /auth/mfa/enroll first"})
+		return
+	}
+
+	if !verifySecondFactor(user, details.Code, "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	if err := userHandler.EnableTOTP(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error enabling two-factor authentication"})
+		return
+	}
+
+	codes, err := recoveryCodeHandler.ReplaceCodes(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating recovery codes"})
+		return
+	}
+
+	// The user just proved they hold the second factor, so upgrade the current session
+	if err := sessionHandler.MarkMFAVerified(sessionId.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating session"})
+		return
+	}
+
+	session, err := sessionHandler.GetSession(sessionId.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating session"})
+		return
+	}
+
+	token, err := middleware.GenerateToken(user, session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MFAConfirmResponse{
+		Message:       "Two-factor authentication enabled",
+		RecoveryCodes: codes,
+		Token:         token,
+	})
+}
+
+// @Summary Disable MFA
+// @Description Turns two-factor authentication off for the current user. Requires a TOTP code or a recovery code. Not allowed for roles where two-factor authentication is mandatory.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param body body MFACodeDetails true "Code from the Authenticator App or a Recovery Code"
+// @security BearerAuth
+// @Success 200 {object} MessageResponse "Two-factor authentication has been disabled."
+// @Failure 400 {object} ErrorResponse "Two-factor authentication is not enabled or the code is invalid."
+// @Failure 401 {object} ErrorResponse "Unauthorized."
+// @Failure 403 {object} ErrorResponse "Two-factor authentication is mandatory for this role."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/mfa/disable [post]
+func DisableMFA(c *gin.Context) {
+	var details MFACodeDetails
+	if err := c.ShouldBindJSON(&details); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
+		return
+	}
+
+	id, _ := c.Get("id")
+
+	user, err := userHandler.GetUser(id.(uint))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !user.IsMFAEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	if middleware.MFARequiredForRole(user.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is mandatory for your role"})
+		return
+	}
+
+	if !verifySecondFactor(user, details.Code, details.RecoveryCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	if err := userHandler.DisableTOTP(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error disabling two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
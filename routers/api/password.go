@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+type ForgotPasswordDetails struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+type ResetPasswordDetails struct {
+	Token    string `json:"token" example:""`
+	Password string `json:"password" example:"newSecurePassword123"`
+}
+
+// @Summary Forgot Password
+// @Description Sends a password reset link to the given email. The response is the same whether or not the email belongs to an account.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordDetails true "Account Email"
+// @Success 200 {object} MessageResponse "The reset link has been sent if the account exists."
+// @Failure 400 {object} ErrorResponse "The request was formatted incorrectly or missing required fields."
+// @Router /auth/forgot-password [post]
+func ForgotPassword(c *gin.Context) {
+	var details ForgotPasswordDetails
+	if err := c.ShouldBindJSON(&details); err != nil || details.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
+		return
+	}
+
+	response := gin.H{"message": "If an account with that email exists, a password reset link has been sent"}
+
+	user, err := userHandler.GetUserByEmail(details.Email)
+	if err != nil || user == nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	token, err := userTokenHandler.CreateToken(user.ID, models.TokenPurposePasswordReset, models.PasswordResetTokenTTL)
+	if err != nil {
+		log.Println("Error creating password reset token:", err)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	link := os.Getenv("APP_URL") + "/reset-password?token=" + token
+	body := fmt.Sprintf(
+		"Hi %s,\n\nSomeone asked to reset the password of your RedRice account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in %d minutes. If you didn't ask for this, you can ignore this email.",
+		user.Name, link, int(models.PasswordResetTokenTTL.Minutes()),
+	)
+
+	if err := mailer.Send(user.Email, "Reset your RedRice password", body); err != nil {
+		log.Println("Error sending password reset mail:", err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Reset Password
+// @Description Sets a new password using the token from the reset link. The token can only be used once, and every session of the account is logged out.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param body body ResetPasswordDetails true "Reset Token and New Password"
+// @Success 200 {object} MessageResponse "The password has been changed."
+// @Failure 400 {object} ErrorResponse "The token is invalid, expired or already used, or the password is too short."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/reset-password [post]
+func ResetPassword(c *gin.Context) {
+	var details ResetPasswordDetails
+	if err := c.ShouldBindJSON(&details); err != nil || details.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
+		return
+	}
+
+	if len(details.Password) < models.MinPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", models.MinPasswordLength)})
+		return
+	}
+
+	userToken, err := userTokenHandler.ConsumeToken(details.Token, models.TokenPurposePasswordReset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reset token is invalid or expired"})
+		return
+	}
+
+	if err := userHandler.UpdatePassword(userToken.UserID, details.Password); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating password"})
+		return
+	}
+
+	if err := sessionHandler.RevokeUserSessions(userToken.UserID); err != nil {
+		log.Println("Error revoking sessions after password reset:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully"})
+}
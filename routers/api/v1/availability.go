@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+type AvailabilityResponse struct {
+	RestaurantID uint          `json:"restaurantId" example:"1"`
+	Date         string        `json:"date" example:"2024-05-01"`
+	PartySize    int           `json:"partySize" example:"2"`
+	Slots        []models.Slot `json:"slots"`
+}
+
+type AvailabilitySearchResponse struct {
+	DateTime    time.Time                       `json:"dateTime"`
+	ExitTime    time.Time                       `json:"exitTime"`
+	PartySize   int                             `json:"partySize" example:"2"`
+	Restaurants []models.RestaurantAvailability `json:"restaurants"`
+}
+
+// partySize is optional and defaults to 1
+func partySizeQuery(c *gin.Context) (int, bool) {
+	value := c.DefaultQuery("partySize", "1")
+	partySize, err := strconv.Atoi(value)
+	if err != nil || partySize <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "partySize must be a number above 0"})
+		return 0, false
+	}
+	return partySize, true
+}
+
+// @Summary Get Restaurant Availability
+// @Description Lists the slots on a day that can still be booked for the party, computed from the opening hours, the tables and the existing reservations. The day is taken in the restaurant's time zone. Slots start every 30 minutes and last 2 hours. Slots the restaurant's booking policy doesn't allow, too soon, too far ahead or for too big a party, are left out.
+// @Tags restaurants
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param date query string true "Day to look at, as YYYY-MM-DD"
+// @Param partySize query int false "Number of guests, defaults to 1"
+// @security BearerAuth
+// @Success 200 {object} AvailabilityResponse "The bookable slots with the number of tables left in each."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID, date or party size."
+// @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while computing availability."
+// @Router /restaurants/{id}/availability [get]
+func GetRestaurantAvailability(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	partySize, ok := partySizeQuery(c)
+	if !ok {
+		return
+	}
+
+	restaurant, err := RestaurantHandler.GetRestaurant(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		return
+	}
+
+	// The day is the day in the restaurant's time zone
+	date, err := time.ParseInLocation("2006-01-02", c.Query("date"), restaurant.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must look like 2024-05-01"})
+		return
+	}
+
+	slots, err := tableHandler.GetAvailability(restaurant, date, partySize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching availability!"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AvailabilityResponse{
+		RestaurantID: restaurant.ID,
+		Date:         date.Format("2006-01-02"),
+		PartySize:    partySize,
+		Slots:        slots,
+	})
+}
+
+// @Summary Search Availability
+// @Description Finds the restaurants that are open and still have a table for the party for 2 hours from the given time. Restaurants whose booking policy doesn't allow the booking, too soon, too far ahead or for too big a party, are left out.
+// @Tags restaurants
+// @Produce json
+// @Param dateTime query string true "Start of the booking, RFC 3339 like 2024-05-01T18:00:00+07:00"
+// @Param partySize query int false "Number of guests, defaults to 1"
+// @security BearerAuth
+// @Success 200 {object} AvailabilitySearchResponse "The restaurants with a free table and how many tables are left."
+// @Failure 400 {object} ErrorResponse "Invalid dateTime or party size."
+// @Failure 500 {object} ErrorResponse "Internal server error while searching."
+// @Router /availability [get]
+func SearchAvailability(c *gin.Context) {
+	dateTime, err := time.Parse(time.RFC3339, c.Query("dateTime"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dateTime must be an RFC 3339 time like 2024-05-01T18:00:00+07:00"})
+		return
+	}
+
+	partySize, ok := partySizeQuery(c)
+	if !ok {
+		return
+	}
+
+	exitTime := dateTime.Add(models.DefaultDiningDuration)
+	restaurants, err := tableHandler.SearchAvailability(dateTime, exitTime, partySize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error searching availability!"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AvailabilitySearchResponse{
+		DateTime:    dateTime,
+		ExitTime:    exitTime,
+		PartySize:   partySize,
+		Restaurants: restaurants,
+	})
+}
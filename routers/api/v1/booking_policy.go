@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"gorm.io/gorm"
+)
+
+var bookingPolicyHandler *models.BookingPolicyHandler
+
+func InitializedBookingPolicyHandler(db *gorm.DB) {
+	bookingPolicyHandler = models.NewBookingPolicyHandler(db)
+}
+
+type BookingPolicyRequest struct {
+	MaxActiveBookings *int `json:"maxActiveBookings" example:"3"`
+	MinLeadMinutes    *int `json:"minLeadMinutes" example:"60"`
+	MaxDaysInAdvance  *int `json:"maxDaysInAdvance" example:"30"`
+	MaxPartySize      *int `json:"maxPartySize" example:"10"`
+	CancellationHours *int `json:"cancellationHours" example:"24"`
+	NoShowLimit       *int `json:"noShowLimit" example:"2"`
+	NoShowPeriodDays  *int `json:"noShowPeriodDays" example:"90"`
+}
+
+type BookingPolicyResponse struct {
+	Policy    *models.BookingPolicy  `json:"policy"`
+	Effective models.EffectivePolicy `json:"effective"`
+}
+
+func (r BookingPolicyRequest) policy() *models.BookingPolicy {
+	return &models.BookingPolicy{
+		MaxActiveBookings: r.MaxActiveBookings,
+		MinLeadMinutes:    r.MinLeadMinutes,
+		MaxDaysInAdvance:  r.MaxDaysInAdvance,
+		MaxPartySize:      r.MaxPartySize,
+		CancellationHours: r.CancellationHours,
+		NoShowLimit:       r.NoShowLimit,
+		NoShowPeriodDays:  r.NoShowPeriodDays,
+	}
+}
+
+// Reply with the stored policy and the rules that end up applying
+func bookingPolicyResponse(c *gin.Context, restaurantID *uint) {
+	policy, err := bookingPolicyHandler.GetPolicy(restaurantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching booking policy"})
+		return
+	}
+
+	var effective models.EffectivePolicy
+	if restaurantID == nil {
+		effective, err = bookingPolicyHandler.GetGlobalPolicy()
+	} else {
+		effective, err = bookingPolicyHandler.GetEffectivePolicy(*restaurantID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching booking policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, BookingPolicyResponse{Policy: policy, Effective: effective})
+}
+
+func saveBookingPolicy(c *gin.Context, restaurantID *uint) {
+	var request BookingPolicyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	policy := request.policy()
+	if err := policy.Validate(); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if err := bookingPolicyHandler.SavePolicy(restaurantID, policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving booking policy"})
+		return
+	}
+
+	bookingPolicyResponse(c, restaurantID)
+}
+
+func restaurantIDParam(c *gin.Context) (*uint, bool) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return nil, false
+	}
+
+	if _, err := RestaurantHandler.GetRestaurant(uint(idInt)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		return nil, false
+	}
+
+	id := uint(idInt)
+	return &id, true
+}
+
+// @Summary Get the Global Booking Policy
+// @Description Returns the default booking rules used by restaurants without their own. A limit of 0 means no limit.
+// @Tags booking policy
+// @Produce json
+// @security BearerAuth
+// @Success 200 {object} BookingPolicyResponse "The stored global policy and the rules that apply."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching the policy."
+// @Router /booking-policy [get]
+func GetGlobalBookingPolicy(c *gin.Context) {
+	bookingPolicyResponse(c, nil)
+}
+
+// @Summary Update the Global Booking Policy
+// @Description Replaces the default booking rules: how many upcoming active bookings a user may hold across all restaurants, how many minutes ahead a booking must be made, how many days ahead it may be made, how many guests a party may have, until how many hours before the booking guests may cancel, and after how many no-shows within how many days a guest can't book anymore. Fields left out fall back to the built-in defaults, 0 means no limit. Only admins can change it.
+// @Tags booking policy
+// @Accept json
+// @Produce json
+// @Param policy body BookingPolicyRequest true "Booking Rules"
+// @security BearerAuth
+// @Success 200 {object} BookingPolicyResponse "The saved policy and the rules that apply."
+// @Failure 400 {object} ValidationErrorResponse "Invalid input format, the fields that are wrong are listed."
+// @Failure 403 {object} ErrorResponse "Only admins can change the global policy."
+// @Failure 500 {object} ErrorResponse "Internal server error while saving the policy."
+// @Router /booking-policy [put]
+func UpdateGlobalBookingPolicy(c *gin.Context) {
+	saveBookingPolicy(c, nil)
+}
+
+// @Summary Get a Restaurant's Booking Policy
+// @Description Returns the restaurant's own booking rules and the rules that apply after falling back to the global policy.
+// @Tags booking policy
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} BookingPolicyResponse "The restaurant's overrides and the rules that apply."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID format."
+// @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching the policy."
+// @Router /restaurants/{id}/booking-policy [get]
+func GetRestaurantBookingPolicy(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+	bookingPolicyResponse(c, restaurantID)
+}
+
+// @Summary Update a Restaurant's Booking Policy
+// @Description Replaces the restaurant's own booking rules. Fields left out fall back to the global policy, 0 means no limit. maxActiveBookings only limits the bookings a user holds at this restaurant, the global limit across all restaurants still applies. Only the restaurant's owner and admins can change it.
+// @Tags booking policy
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param policy body BookingPolicyRequest true "Booking Rules"
+// @security BearerAuth
+// @Success 200 {object} BookingPolicyResponse "The saved overrides and the rules that apply."
+// @Failure 400 {object} ValidationErrorResponse "Invalid input format, the fields that are wrong are listed."
+// @Failure 403 {object} ErrorResponse "Not allowed to edit this restaurant."
+// @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while saving the policy."
+// @Router /restaurants/{id}/booking-policy [put]
+func UpdateRestaurantBookingPolicy(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+	saveBookingPolicy(c, restaurantID)
+}
@@ -0,0 +1,250 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"github.com/punchanabu/redrice-backend-go/utils"
+)
+
+// How far back the feeds go, older bookings are of no use in a calendar
+const calendarFeedHistory = 90 * 24 * time.Hour
+
+type CalendarFeedResponse struct {
+	URL   string `json:"url" example:"https://api.redrice.app/api/v1/users/1/reservations.ics?token=abc"`
+	Token string `json:"token" example:"abc"`
+}
+
+// The same reservation always gets the same UID, so calendars update the event instead of adding a new one
+func reservationEvent(reservation *models.Reservation, forRestaurant bool) utils.ICalEvent {
+	event := utils.ICalEvent{
+		UID:          fmt.Sprintf("reservation-%d@redrice", reservation.ID),
+		Sequence:     reservation.Sequence,
+		Start:        reservation.DateTime,
+		End:          reservation.ExitTime,
+		LastModified: reservation.UpdatedAt,
+	}
+
+	if forRestaurant {
+		event.Summary = fmt.Sprintf("%s, party of %d", reservation.User.Name, reservation.PartySize)
+	} else {
+		event.Summary = fmt.Sprintf("Table for %d at %s", reservation.PartySize, reservation.Restaurant.Name)
+	}
+
+	event.Location = reservation.Restaurant.Name
+	if reservation.Restaurant.Address != "" {
+		event.Location += ", " + reservation.Restaurant.Address
+	}
+
+	lines := []string{
+		fmt.Sprintf("Table %d", reservation.TableNum),
+		fmt.Sprintf("Party of %d", reservation.PartySize),
+		"Status: " + string(reservation.Status),
+	}
+	if len(reservation.DietaryFlags) > 0 {
+		flags := make([]string, len(reservation.DietaryFlags))
+		for i, flag := range reservation.DietaryFlags {
+			flags[i] = string(flag)
+		}
+		lines = append(lines, "Dietary: "+strings.Join(flags, ", "))
+	}
+	if reservation.SpecialRequests != "" {
+		lines = append(lines, "Requests: "+reservation.SpecialRequests)
+	}
+	event.Description = strings.Join(lines, "\n")
+
+	switch {
+	case reservation.DeletedAt.Valid:
+		// Deleting doesn't bump the sequence, so do it here or calendars would keep the old event
+		event.Status = "CANCELLED"
+		event.Sequence++
+		event.LastModified = reservation.DeletedAt.Time
+	case reservation.Status == models.StatusCancelled, reservation.Status == models.StatusNoShow:
+		event.Status = "CANCELLED"
+	case reservation.Status == models.StatusPending:
+		event.Status = "TENTATIVE"
+	default:
+		event.Status = "CONFIRMED"
+	}
+
+	return event
+}
+
+func writeCalendar(c *gin.Context, filename string, name string, reservations []models.Reservation, forRestaurant bool) {
+	events := make([]utils.ICalEvent, len(reservations))
+	for i := range reservations {
+		events[i] = reservationEvent(&reservations[i], forRestaurant)
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(utils.ICalendar(name, events)))
+}
+
+// Feed urls point back at this api, behind a proxy we trust its forwarded scheme
+func calendarFeedURL(c *gin.Context, path string, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s/api/v1%s?token=%s", scheme, c.Request.Host, path, token)
+}
+
+// Wrong tokens and unknown ids look the same, so feed urls can't be guessed one part at a time
+func validCalendarToken(tokenHash string, token string) bool {
+	if tokenHash == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(tokenHash), []byte(utils.HashToken(token))) == 1
+}
+
+// Served by GetReservation, gin can't route /reservations/:id.ics next to /reservations/:id
+// @Summary Get a Reservation as iCalendar
+// @Description Downloads a single reservation as an RFC 5545 .ics file to add to Google, Apple or Outlook calendar. Only the guest who booked, the restaurant's staff and admins can get it.
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "Reservation ID"
+// @security BearerAuth
+// @Success 200 {string} string "The reservation as a VCALENDAR."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this reservation."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Router /reservations/{id}.ics [get]
+func reservationCalendar(c *gin.Context, reservation *models.Reservation) {
+	writeCalendar(c, fmt.Sprintf("reservation-%d.ics", reservation.ID), "RedRice reservation", []models.Reservation{*reservation}, false)
+}
+
+// @Summary Create my Calendar Feed
+// @Description Creates a secret url with all of the current user's reservations in iCalendar format, to subscribe to from a calendar app. Calling it again replaces the token and the old url stops working. The token is only shown once.
+// @Tags calendar
+// @Produce json
+// @security BearerAuth
+// @Success 200 {object} CalendarFeedResponse "The feed url and its token."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /me/calendar-feed [post]
+func CreateMyCalendarFeed(c *gin.Context) {
+	id, _ := c.Get("id")
+
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		return
+	}
+
+	if err := userHandler.SetCalendarToken(id.(uint), utils.HashToken(token)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CalendarFeedResponse{
+		URL:   calendarFeedURL(c, fmt.Sprintf("/users/%d/reservations.ics", id.(uint)), token),
+		Token: token,
+	})
+}
+
+// @Summary Get a User's Calendar Feed
+// @Description Returns the user's reservations from the last 90 days on as an iCalendar feed. Authenticated by the secret token from /me/calendar-feed instead of a bearer token, so calendar apps can poll it. Cancelled and deleted reservations stay in the feed as cancelled events.
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "User ID"
+// @Param token query string true "Feed Token"
+// @Success 200 {string} string "The reservations as a VCALENDAR."
+// @Failure 404 {object} ErrorResponse "No feed with this id and token."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /users/{id}/reservations.ics [get]
+func GetUserCalendarFeed(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	user, err := userHandler.GetUser(uint(idInt))
+	if err != nil || !validCalendarToken(user.CalendarToken, c.Query("token")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	reservations, err := reservationHandler.GetCalendarReservations(user.ID, 0, time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservations"})
+		return
+	}
+
+	writeCalendar(c, "reservations.ics", "RedRice reservations", reservations, false)
+}
+
+// @Summary Create a Restaurant's Calendar Feed
+// @Description Creates a secret url with every booking at the restaurant in iCalendar format, for the owner to share with the staff's calendars. Calling it again replaces the token and the old url stops working. The token is only shown once.
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @security BearerAuth
+// @Success 200 {object} CalendarFeedResponse "The feed url and its token."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID format."
+// @Failure 403 {object} ErrorResponse "Only admins and the restaurant's owner can create the feed."
+// @Failure 404 {object} ErrorResponse "Restaurant not found."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /restaurants/{id}/calendar-feed [post]
+func CreateRestaurantCalendarFeed(c *gin.Context) {
+	restaurantID, ok := restaurantIDParam(c)
+	if !ok {
+		return
+	}
+
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		return
+	}
+
+	if err := RestaurantHandler.SetCalendarToken(*restaurantID, utils.HashToken(token)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CalendarFeedResponse{
+		URL:   calendarFeedURL(c, fmt.Sprintf("/restaurants/%d/reservations.ics", *restaurantID), token),
+		Token: token,
+	})
+}
+
+// @Summary Get a Restaurant's Calendar Feed
+// @Description Returns every booking at the restaurant from the last 90 days on as an iCalendar feed, with the guest, party size, dietary flags and special requests. Authenticated by the secret token from /restaurants/{id}/calendar-feed instead of a bearer token.
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "Restaurant ID"
+// @Param token query string true "Feed Token"
+// @Success 200 {string} string "The bookings as a VCALENDAR."
+// @Failure 404 {object} ErrorResponse "No feed with this id and token."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /restaurants/{id}/reservations.ics [get]
+func GetRestaurantCalendarFeed(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	restaurant, err := RestaurantHandler.GetRestaurant(uint(idInt))
+	if err != nil || !validCalendarToken(restaurant.CalendarToken, c.Query("token")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	reservations, err := reservationHandler.GetCalendarReservations(0, restaurant.ID, time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservations"})
+		return
+	}
+
+	writeCalendar(c, "bookings.ics", restaurant.Name+" bookings", reservations, true)
+}
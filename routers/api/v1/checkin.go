@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"github.com/skip2/go-qrcode"
+)
+
+type CheckInDetails struct {
+	Token string `json:"token" example:"AAAAAQAAAAIAAAAAZjJ7YNc1..."`
+}
+
+// @Summary Get the Check-in QR Code of a Reservation
+// @Description Returns a PNG QR code for the guest to show at the front desk. The code holds an encrypted token for this booking and stops working when the booking is moved to another time. Only confirmed reservations get a code. Only the guest who booked, the restaurant's staff and admins can get it.
+// @Tags reservations
+// @Produce png
+// @Param id path int true "Reservation ID"
+// @Param size query int false "Width and height in pixels, 128 to 1024, defaults to 256"
+// @security BearerAuth
+// @Success 200 {file} file "The QR code."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID or size."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this reservation."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The reservation is not confirmed."
+// @Failure 500 {object} ErrorResponse "Internal server error while making the QR code."
+// @Router /reservations/{id}/qr [get]
+func GetReservationQR(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation id"})
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "256"))
+	if err != nil || size < 128 || size > 1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "size must be between 128 and 1024"})
+		return
+	}
+
+	reservation, err := reservationHandler.GetReservation(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	if !canAccessReservation(middleware.GetClaims(c), reservation, middleware.PermRestaurantReservationsRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to see this reservation"})
+		return
+	}
+
+	if reservation.Status != models.StatusConfirmed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only confirmed reservations can be checked in"})
+		return
+	}
+
+	token, err := middleware.GenerateCheckInToken(middleware.CheckInClaims{
+		ReservationID: reservation.ID,
+		RestaurantID:  reservation.RestaurantID,
+		DateTime:      reservation.DateTime,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error making QR code"})
+		return
+	}
+
+	png, err := qrcode.Encode(token, qrcode.Medium, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error making QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// @Summary Check in a Guest
+// @Description Reads the token from a guest's QR code and marks the reservation as seated. The code has to be for this restaurant, for the booking's current time and for today in the restaurant's time zone. Only available to admins and the restaurant's owner and staff.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param body body CheckInDetails true "Token from the QR Code"
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation, now seated."
+// @Failure 400 {object} ErrorResponse "The code is not valid or is for another restaurant."
+// @Failure 403 {object} ErrorResponse "Not allowed to manage this restaurant's reservations."
+// @Failure 404 {object} ErrorResponse "The reservation of the code no longer exists."
+// @Failure 409 {object} ErrorResponse "The code is outdated, not for today, or the reservation can't be seated from its current status."
+// @Router /restaurants/{id}/check-in [post]
+func CheckInReservation(c *gin.Context) {
+	restaurantID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	var details CheckInDetails
+	if err := c.ShouldBindJSON(&details); err != nil || details.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
+		return
+	}
+
+	claims, err := middleware.ValidateCheckInToken(details.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if claims.RestaurantID != uint(restaurantID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This check-in code is for another restaurant"})
+		return
+	}
+
+	reservation, err := reservationHandler.GetReservation(claims.ReservationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	// A booking that was moved gets a new code, the old one shouldn't seat anybody.
+	// The code only keeps whole seconds of the start time.
+	if reservation.RestaurantID != claims.RestaurantID || reservation.DateTime.Unix() != claims.DateTime.Unix() {
+		c.JSON(http.StatusConflict, gin.H{"error": "This check-in code is outdated, please show the latest one"})
+		return
+	}
+
+	loc := reservation.Restaurant.Location()
+	if reservation.DateTime.In(loc).Format("2006-01-02") != time.Now().In(loc).Format("2006-01-02") {
+		c.JSON(http.StatusConflict, gin.H{"error": "This reservation is not for today"})
+		return
+	}
+
+	changeReservationStatus(c, reservation, models.StatusSeated, false)
+}
@@ -0,0 +1,175 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+// Read the from, to, status and tableNum filters, days are taken in the restaurant's time zone
+func reservationFilterQuery(c *gin.Context, restaurant *models.Restaurant) (models.ReservationFilter, bool) {
+	var filter models.ReservationFilter
+
+	if from := c.Query("from"); from != "" {
+		day, err := time.ParseInLocation("2006-01-02", from, restaurant.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must look like 2024-05-01"})
+			return filter, false
+		}
+		filter.From = day
+	}
+
+	if to := c.Query("to"); to != "" {
+		day, err := time.ParseInLocation("2006-01-02", to, restaurant.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must look like 2024-05-01"})
+			return filter, false
+		}
+		// to is the last day that is included
+		filter.To = day.AddDate(0, 0, 1)
+	}
+
+	if status := c.Query("status"); status != "" {
+		for _, value := range strings.Split(status, ",") {
+			status := models.ReservationStatus(strings.TrimSpace(value))
+			if !status.IsValid() {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown status " + string(status)})
+				return filter, false
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if tableNum := c.Query("tableNum"); tableNum != "" {
+		number, err := strconv.Atoi(tableNum)
+		if err != nil || number <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "tableNum must be a number above 0"})
+			return filter, false
+		}
+		filter.TableNum = number
+	}
+
+	return filter, true
+}
+
+// @Summary Get a Restaurant's Day
+// @Description The day view of the dashboard. Counts the covers (guests) for every hour the restaurant is open and how long every table is booked, next to all the bookings of the day. Cancelled bookings and no-shows are listed but not counted. Only available to admins and the restaurant's owner and staff.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param date query string false "Day to look at, as YYYY-MM-DD, defaults to today in the restaurant's time zone"
+// @security BearerAuth
+// @Success 200 {object} models.DayView "Covers per hour, table occupancy and the bookings of the day."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID or date."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this restaurant's reservations."
+// @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching reservations."
+// @Router /restaurants/{id}/reservations/day [get]
+func GetRestaurantDayView(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	restaurant, err := RestaurantHandler.GetRestaurant(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		return
+	}
+
+	date := time.Now().In(restaurant.Location())
+	if value := c.Query("date"); value != "" {
+		date, err = time.ParseInLocation("2006-01-02", value, restaurant.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must look like 2024-05-01"})
+			return
+		}
+	}
+
+	view, err := reservationHandler.GetDayView(restaurant, date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservations for restaurant"})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// Change the status of a booking from the dashboard, the booking has to be at the restaurant in the url
+func transitionRestaurantReservation(c *gin.Context, to models.ReservationStatus) {
+	restaurantID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	idInt, err := strconv.Atoi(c.Param("reservationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation id"})
+		return
+	}
+
+	reservation, err := reservationHandler.GetReservation(uint(idInt))
+	if err != nil || reservation.RestaurantID != uint(restaurantID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	changeReservationStatus(c, reservation, to, false)
+}
+
+// @Summary Confirm a Booking from the Dashboard
+// @Description Moves a pending booking at the restaurant to confirmed. Only available to admins and the restaurant's owner and staff.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param reservationId path int true "Reservation ID"
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation with its new status."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant or reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to manage this restaurant's reservations."
+// @Failure 404 {object} ErrorResponse "Reservation not found at this restaurant."
+// @Failure 409 {object} ErrorResponse "The reservation can't be confirmed from its current status."
+// @Router /restaurants/{id}/reservations/{reservationId}/confirm [post]
+func ConfirmRestaurantReservation(c *gin.Context) {
+	transitionRestaurantReservation(c, models.StatusConfirmed)
+}
+
+// @Summary Seat a Booking from the Dashboard
+// @Description Marks a confirmed booking at the restaurant as seated when the guest arrives. Only available to admins and the restaurant's owner and staff.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param reservationId path int true "Reservation ID"
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation with its new status."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant or reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to manage this restaurant's reservations."
+// @Failure 404 {object} ErrorResponse "Reservation not found at this restaurant."
+// @Failure 409 {object} ErrorResponse "The reservation can't be seated from its current status."
+// @Router /restaurants/{id}/reservations/{reservationId}/seat [post]
+func SeatRestaurantReservation(c *gin.Context) {
+	transitionRestaurantReservation(c, models.StatusSeated)
+}
+
+// @Summary Cancel a Booking from the Dashboard
+// @Description Cancels a pending or confirmed booking at the restaurant, the freed table is offered to the waitlist. Staff aren't bound by the cancellation deadline. Only available to admins and the restaurant's owner and staff.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param reservationId path int true "Reservation ID"
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation with its new status."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant or reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to manage this restaurant's reservations."
+// @Failure 404 {object} ErrorResponse "Reservation not found at this restaurant."
+// @Failure 409 {object} ErrorResponse "The reservation can't be cancelled from its current status."
+// @Router /restaurants/{id}/reservations/{reservationId}/cancel [post]
+func CancelRestaurantReservation(c *gin.Context) {
+	transitionRestaurantReservation(c, models.StatusCancelled)
+}
@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+type ScheduleInterval struct {
+	Weekday   int    `json:"weekday" example:"1"`
+	OpenTime  string `json:"openTime" example:"11:00"`
+	CloseTime string `json:"closeTime" example:"14:00"`
+}
+
+type ScheduleRequest struct {
+	TimeZone  string             `json:"timeZone" example:"Asia/Bangkok"`
+	Intervals []ScheduleInterval `json:"intervals"`
+}
+
+type ExceptionRequest struct {
+	Date      string `json:"date" example:"2024-12-31"`
+	Closed    bool   `json:"closed" example:"false"`
+	OpenTime  string `json:"openTime" example:"18:00"`
+	CloseTime string `json:"closeTime" example:"02:00"`
+	Note      string `json:"note" example:"New Year's Eve"`
+}
+
+// @Summary Replace the Weekly Schedule
+// @Description Replaces the weekly opening hours of a restaurant. A weekday (0 is Sunday) can have several intervals, like lunch and dinner, and a closeTime before the openTime closes after midnight. Intervals can't overlap, also not when one runs past midnight into the next day's. Weekdays without intervals are closed. The time zone is changed too when one is given. Only the restaurant's owner and admins can edit the schedule.
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param schedule body ScheduleRequest true "Weekly Schedule"
+// @security BearerAuth
+// @Success 200 {object} models.Restaurant "The restaurant with its new schedule."
+// @Failure 400 {object} ValidationErrorResponse "Invalid input format, the fields that are wrong are listed."
+// @Failure 403 {object} ErrorResponse "Not allowed to edit this restaurant."
+// @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while saving the schedule."
+// @Router /restaurants/{id}/schedule [put]
+func UpdateRestaurantSchedule(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	var request ScheduleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	if request.TimeZone != "" {
+		if err := models.ValidateTimeZone(request.TimeZone); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
+	intervals := make([]models.OpeningInterval, 0, len(request.Intervals))
+	for _, interval := range request.Intervals {
+		intervals = append(intervals, models.OpeningInterval{
+			Weekday:   interval.Weekday,
+			OpenTime:  interval.OpenTime,
+			CloseTime: interval.CloseTime,
+		})
+	}
+	intervals, err = models.NormalizeSchedule(intervals)
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if _, err := RestaurantHandler.GetRestaurant(uint(idInt)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		return
+	}
+
+	if err := RestaurantHandler.ReplaceSchedule(uint(idInt), request.TimeZone, intervals); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving schedule"})
+		return
+	}
+
+	restaurant, err := RestaurantHandler.GetRestaurant(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching restaurant"})
+		return
+	}
+
+	c.JSON(http.StatusOK, restaurant)
+}
+
+// @Summary Add an Opening Exception
+// @Description Changes the opening hours of a single date, like a public holiday. Either closes the whole day or replaces the weekly schedule of that day with special hours. Several exceptions on one date add up to several intervals. Only the restaurant's owner and admins can add exceptions.
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param exception body ExceptionRequest true "Exception Details"
+// @security BearerAuth
+// @Success 201 {object} models.OpeningException "The created exception."
+// @Failure 400 {object} ValidationErrorResponse "Invalid input format, the fields that are wrong are listed."
+// @Failure 403 {object} ErrorResponse "Not allowed to edit this restaurant."
+// @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while saving the exception."
+// @Router /restaurants/{id}/exceptions [post]
+func CreateOpeningException(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	var request ExceptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	exception := models.OpeningException{
+		RestaurantID: uint(idInt),
+		Date:         request.Date,
+		Closed:       request.Closed,
+		OpenTime:     request.OpenTime,
+		CloseTime:    request.CloseTime,
+		Note:         request.Note,
+	}
+	if err := models.NormalizeException(&exception); err != nil {
+		validationError(c, err)
+		return
+	}
+
+	if _, err := RestaurantHandler.GetRestaurant(uint(idInt)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		return
+	}
+
+	if err := RestaurantHandler.CreateException(&exception); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving exception"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, exception)
+}
+
+// @Summary Delete an Opening Exception
+// @Description Removes a dated exception so the weekly schedule applies again. Only the restaurant's owner and admins can remove exceptions.
+// @Tags restaurants
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param exceptionId path int true "Exception ID" Format(int64)
+// @security BearerAuth
+// @Success 204 "Exception successfully deleted, no content to return."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID or exception ID."
+// @Failure 403 {object} ErrorResponse "Not allowed to edit this restaurant."
+// @Failure 404 {object} ErrorResponse "Exception not found at this restaurant."
+// @Failure 500 {object} ErrorResponse "Internal server error while deleting the exception."
+// @Router /restaurants/{id}/exceptions/{exceptionId} [delete]
+func DeleteOpeningException(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	exceptionID, err := strconv.Atoi(c.Param("exceptionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exception id"})
+		return
+	}
+
+	if err := RestaurantHandler.DeleteException(uint(idInt), uint(exceptionID)); err != nil {
+		if errors.Is(err, models.ErrExceptionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exception not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting exception"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
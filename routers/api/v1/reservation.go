@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,18 +18,58 @@ func InitializedReservationHandler(db *gorm.DB) {
 	reservationHandler = models.NewReservationHandler(db)
 }
 
+// The guest who booked, admins and the restaurant's staff can work with a reservation
+func canAccessReservation(claims *middleware.Claims, reservation *models.Reservation, permission middleware.Permission) bool {
+	return reservation.UserID == claims.UserId || middleware.CanAccessRestaurant(claims, permission, reservation.RestaurantID)
+}
+
+type ConflictResponse struct {
+	Error    string                           `json:"error" example:"table 4 is already booked from 2024-05-01T18:00:00Z to 2024-05-01T20:00:00Z"`
+	Conflict *models.ReservationConflictError `json:"conflict"`
+}
+
+// Turn the errors from booking a table into the right status code, anything unknown is a 500
+func abortBookingError(c *gin.Context, err error, message string) {
+	var conflict *models.ReservationConflictError
+	var invalid *models.ValidationError
+	var limit *models.BookingLimitError
+	var restricted *models.BookingRestrictedError
+	var tooLate *models.CancellationWindowError
+	switch {
+	case errors.As(err, &limit):
+		c.JSON(http.StatusForbidden, gin.H{"error": limit.Error()})
+	case errors.As(err, &restricted):
+		c.JSON(http.StatusForbidden, gin.H{"error": restricted.Error()})
+	case errors.As(err, &tooLate):
+		c.JSON(http.StatusForbidden, gin.H{"error": tooLate.Error()})
+	case errors.As(err, &invalid):
+		validationError(c, err)
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, ConflictResponse{Error: conflict.Error(), Conflict: conflict})
+	case errors.Is(err, models.ErrTableNotFound), errors.Is(err, models.ErrNoTables):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrReservationNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 // @Summary Get a Single Reservation
-// @Description Retrieves details of a single reservation by its unique identifier.
+// @Description Retrieves details of a single reservation by its unique identifier. Only the guest who booked, the restaurant's staff and admins can see it.
 // @Tags reservations
 // @Produce json
 // @Param id path int true "Reservation ID" Format(int64)
 // @security BearerAuth
 // @Success 200 {object} models.Reservation "The details of the reservation including ID, DateTime, UserID, User, RestaurantID, and Restaurant."
 // @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this reservation."
 // @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
 // @Router /reservations/{id} [get]
 func GetReservation(c *gin.Context) {
-	idString := c.Param("id")
+	idString, ics := strings.CutSuffix(c.Param("id"), ".ics")
 	idInt, err := strconv.Atoi(idString)
 
 	if err != nil {
@@ -43,11 +84,20 @@ func GetReservation(c *gin.Context) {
 		return
 	}
 
+	if !canAccessReservation(middleware.GetClaims(c), reservation, middleware.PermRestaurantReservationsRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to see this reservation"})
+		return
+	}
+
+	if ics {
+		reservationCalendar(c, reservation)
+		return
+	}
 	c.JSON(http.StatusOK, reservation)
 }
 
 // @Summary Get All Reservations
-// @Description Retrieves a list of all reservations in the system.
+// @Description Retrieves a list of all reservations in the system for admins. Everyone else only gets their own reservations.
 // @Tags reservations
 // @Produce json
 // @security BearerAuth
@@ -55,7 +105,15 @@ func GetReservation(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "Internal server error while fetching reservations."
 // @Router /reservations [get]
 func GetReservations(c *gin.Context) {
-	reservations, err := reservationHandler.GetReservations()
+	claims := middleware.GetClaims(c)
+
+	var reservations []models.Reservation
+	var err error
+	if middleware.HasGlobalAccess(claims, middleware.PermRestaurantReservationsRead) {
+		reservations, err = reservationHandler.GetReservations()
+	} else {
+		reservations, err = reservationHandler.GetReservationsByUserID(claims.UserId)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservations!"})
 		return
@@ -64,14 +122,16 @@ func GetReservations(c *gin.Context) {
 }
 
 // @Summary Create a New Reservation
-// @Description Adds a new reservation to the system with the provided details. This endpoint requires authentication.
+// @Description Adds a new reservation to the system with the provided details. This endpoint requires authentication. When tableNum is left out, the smallest free table that seats partySize (1 when left out) for the whole slot is picked. dietaryFlags can be vegetarian, vegan, halal, kosher, gluten_free, dairy_free, nut_allergy and shellfish_allergy, anything else goes into specialRequests (at most 500 characters).
 // @Tags reservations
 // @Accept json
 // @Produce json
 // @Param reservation body models.Reservation true "Reservation Details"
 // @security BearerAuth
 // @Success 201 {object} models.Reservation "The created reservation's details, including its unique identifier."
-// @Failure 400 {object} ErrorResponse "Invalid input format for reservation details."
+// @Failure 400 {object} ValidationErrorResponse "Invalid reservation details, the fields that are wrong are listed. Reservations must be in the future, end after they start, fit in the opening hours and follow the restaurant's booking policy."
+// @Failure 403 {object} ErrorResponse "The user already has as many active reservations as the booking policy allows, or is restricted after too many no-shows."
+// @Failure 409 {object} ConflictResponse "The slot overlaps another booking, the conflicting slot is returned."
 // @Failure 500 {object} ErrorResponse "Internal server error while creating the reservation."
 // @Router /reservations [post]
 func CreateReservation(c *gin.Context) {
@@ -93,30 +153,12 @@ func CreateReservation(c *gin.Context) {
 		return
 	}
 
-	authHeader := c.GetHeader("Authorization")
-	parts := strings.SplitN(authHeader, " ", 2)
-	tokenString := parts[1]
-	claims, err := middleware.ValidateToken(tokenString)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized 🥹 Please login first!"})
-		c.Abort()
-		return
-	}
+	// Admins can book past the limit of active bookings
+	enforceLimit := middleware.GetClaims(c).Role != models.RoleAdmin
 
-	OwnReservations, err := reservationHandler.GetReservationsByUserID(uint(uid)) // Correctly cast to uint now
+	err := reservationHandler.CreateReservation(uid, &reservation, enforceLimit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservations for user"})
-		return
-	}
-
-	if len(OwnReservations) == 3 && claims.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "User already has 3 reservations. Cannot create more."})
-		return
-	}
-
-	err = reservationHandler.CreateReservation(uid, &reservation)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating reservation"})
+		abortBookingError(c, err, "Error creating reservation")
 		return
 	}
 
@@ -124,18 +166,26 @@ func CreateReservation(c *gin.Context) {
 }
 
 // @Summary Update a Reservation
-// @Description Updates the details of an existing reservation identified by its ID. This endpoint requires authentication.
+// @Description Updates the details of an existing reservation identified by its ID. Only the guest who booked, the restaurant's staff and admins can update it. For a recurring reservation, scope=following also updates the later occurrences and scope=all every occurrence that hasn't happened yet. A new dateTime moves them by the same number of days and to the same time of day, occurrences that can't be moved are listed in conflicts and keep their old slot. The series itself moves along, so it keeps describing its coming occurrences. If one occurrence can't be changed because of an unexpected error, none of them are. An occurrence moved to another restaurant leaves its series.
 // @Tags reservations
 // @Accept json
 // @Produce json
 // @Param id path int true "Reservation ID" Format(int64)
+// @Param scope query string false "this (default), following or all"
 // @Param reservation body models.Reservation true "Updated Reservation Details"
 // @security BearerAuth
-// @Success 200 {object} models.Reservation "The updated reservation's details."
-// @Failure 400 {object} ErrorResponse "Invalid input format for reservation details or invalid reservation ID."
+// @Success 200 {object} models.Reservation "The updated reservation's details. With scope following or all a models.SeriesChangeResult."
+// @Failure 400 {object} ValidationErrorResponse "Invalid reservation details, reservation ID or scope, the fields that are wrong are listed, or the reservation is not part of a series."
+// @Failure 403 {object} ErrorResponse "Not allowed to update this reservation, or the guest's cancellation deadline has passed and the booking can't be moved anymore."
 // @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 409 {object} ConflictResponse "The new slot overlaps another booking, the conflicting slot is returned, or the reservation is no longer active."
 // @Router /reservations/{id} [put]
 func UpdateReservation(c *gin.Context) {
+	scope, ok := seriesScope(c)
+	if !ok {
+		return
+	}
+
 	var reservation models.Reservation
 	if err := c.ShouldBindJSON(&reservation); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -152,9 +202,36 @@ func UpdateReservation(c *gin.Context) {
 
 	idUint := uint(idInt)
 
-	err = reservationHandler.UpdateReservation(idUint, &reservation)
+	ownReservation, err := reservationHandler.GetReservation(idUint)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating reservation"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+	if !canAccessReservation(claims, ownReservation, middleware.PermRestaurantReservationsManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to update this reservation"})
+		return
+	}
+
+	// A reservation can't be handed over to someone else, and staff can't move it to another restaurant.
+	// The status only changes through the transition endpoints.
+	reservation.UserID = 0
+	reservation.Status = ""
+	reservation.StatusChangedAt = nil
+	reservation.StatusChangedByID = nil
+	if ownReservation.UserID != claims.UserId && !middleware.HasGlobalAccess(claims, middleware.PermRestaurantReservationsManage) {
+		reservation.RestaurantID = 0
+	}
+
+	if scope != models.ScopeThis {
+		updateReservationSeries(c, ownReservation, &reservation, scope)
+		return
+	}
+
+	err = reservationHandler.UpdateReservation(idUint, &reservation, claims.UserId)
+	if err != nil {
+		abortBookingError(c, err, "Error updating reservation")
 		return
 	}
 
@@ -162,14 +239,16 @@ func UpdateReservation(c *gin.Context) {
 }
 
 // @Summary Delete a Reservation
-// @Description Removes a reservation from the system by its unique identifier. This endpoint requires authentication.
+// @Description Only admins remove the reservation from the system. For the guest who booked and the restaurant's staff it is cancelled like with /reservations/{id}/cancel and the cancelled reservation is returned, so it keeps its status changes and history.
 // @Tags reservations
 // @Produce json
 // @Param id path int true "Reservation ID" Format(int64)
 // @security BearerAuth
-// @Success 204 "Reservation successfully deleted, no content to return."
+// @Success 200 {object} models.Reservation "The cancelled reservation, when not deleted by an admin."
 // @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to delete this reservation, or the guest's cancellation deadline has passed."
 // @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The reservation can't be cancelled from its current status."
 // @Router /reservations/{id} [delete]
 func DeleteReservation(c *gin.Context) {
 	idString := c.Param("id")
@@ -182,8 +261,31 @@ func DeleteReservation(c *gin.Context) {
 
 	idUint := uint(idInt)
 
-	err = reservationHandler.DeleteReservation(idUint)
+	ownReservation, err := reservationHandler.GetReservation(idUint)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+	if !canAccessReservation(claims, ownReservation, middleware.PermRestaurantReservationsManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to delete this reservation"})
+		return
+	}
+
+	// Everyone but admins goes through the lifecycle, a deleted booking would lose its status changes
+	if !middleware.HasGlobalAccess(claims, middleware.PermRestaurantReservationsManage) {
+		changeReservationStatus(c, ownReservation, models.StatusCancelled, true)
+		return
+	}
+
+	err = reservationHandler.DeleteReservation(idUint, claims.UserId)
 	if err != nil {
+		var tooLate *models.CancellationWindowError
+		if errors.As(err, &tooLate) {
+			c.JSON(http.StatusForbidden, gin.H{"error": tooLate.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting reservation"})
 		return
 	}
@@ -193,13 +295,14 @@ func DeleteReservation(c *gin.Context) {
 
 // GetUserReservations retrieves all reservations for a given user ID.
 // @Summary Get User's Reservations
-// @Description Retrieves a list of reservations associated with a specific user.
+// @Description Retrieves a list of reservations associated with a specific user. Users can only list their own reservations, admins can list anyone's.
 // @Tags reservations
 // @Produce json
 // @Param userId path int true "User ID"
 // @security BearerAuth
 // @Success 200 {array} models.Reservation "An array of reservation objects for the user."
 // @Failure 400 {object} ErrorResponse "Invalid user ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this user's reservations."
 // @Failure 404 {object} ErrorResponse "Reservations not found for the specified user ID."
 // @Router /users/{userId}/reservations [get]
 func GetUserReservations(c *gin.Context) {
@@ -215,6 +318,12 @@ func GetUserReservations(c *gin.Context) {
 		return
 	}
 
+	claims := middleware.GetClaims(c)
+	if claims.UserId != uint(uid) && !middleware.HasGlobalAccess(claims, middleware.PermRestaurantReservationsRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to see this user's reservations"})
+		return
+	}
+
 	reservations, err := reservationHandler.GetReservationsByUserID(uint(uid)) // Correctly cast to uint now
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservations for user"})
@@ -223,3 +332,47 @@ func GetUserReservations(c *gin.Context) {
 
 	c.JSON(http.StatusOK, reservations)
 }
+
+// GetRestaurantReservations retrieves the reservations of a given restaurant ID.
+// @Summary Get Restaurant's Reservations
+// @Description Retrieves a list of reservations made at a specific restaurant, with the party size, dietary flags and special requests of every booking. Can be narrowed down to a date range in the restaurant's time zone, some statuses and a table. Only available to admins and the restaurant's owner and staff.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param from query string false "First day, as YYYY-MM-DD"
+// @Param to query string false "Last day, as YYYY-MM-DD"
+// @Param status query string false "Comma separated statuses, like pending,confirmed"
+// @Param tableNum query int false "Table number"
+// @security BearerAuth
+// @Success 200 {array} models.Reservation "An array of reservation objects for the restaurant."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID or filter."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this restaurant's reservations."
+// @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching reservations."
+// @Router /restaurants/{id}/reservations [get]
+func GetRestaurantReservations(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error parsing restaurant ID"})
+		return
+	}
+
+	restaurant, err := RestaurantHandler.GetRestaurant(uint(restaurantID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		return
+	}
+
+	filter, ok := reservationFilterQuery(c, restaurant)
+	if !ok {
+		return
+	}
+
+	reservations, err := reservationHandler.GetReservationsByRestaurantID(restaurant.ID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservations for restaurant"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reservations)
+}
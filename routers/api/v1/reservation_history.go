@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
+)
+
+// @Summary Get Reservation History
+// @Description Lists every change made to a reservation, from its creation to its deletion, with who made it, when, and the fields that changed from what to what. Deleted reservations keep their history. Only the guest who booked, the restaurant's staff and admins can see it.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {array} models.ReservationHistory "The history entries, oldest first."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this reservation."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching the history."
+// @Router /reservations/{id}/history [get]
+func GetReservationHistory(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation id"})
+		return
+	}
+
+	reservation, err := reservationHandler.GetReservationWithDeleted(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	if !canAccessReservation(middleware.GetClaims(c), reservation, middleware.PermRestaurantReservationsRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to see this reservation"})
+		return
+	}
+
+	history, err := reservationHandler.GetHistory(reservation.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching reservation history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
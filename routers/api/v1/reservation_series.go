@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+type SeriesResponse struct {
+	Series    models.ReservationSeries    `json:"series"`
+	Conflicts []models.OccurrenceConflict `json:"conflicts"`
+}
+
+type SeriesConflictResponse struct {
+	Error     string                      `json:"error" example:"none of the occurrences could be booked"`
+	Conflicts []models.OccurrenceConflict `json:"conflicts"`
+}
+
+// The occurrences an edit or cancellation applies to, just the one in the url when left out
+func seriesScope(c *gin.Context) (models.SeriesScope, bool) {
+	scope := models.SeriesScope(c.DefaultQuery("scope", string(models.ScopeThis)))
+	switch scope {
+	case models.ScopeThis, models.ScopeFollowing, models.ScopeAll:
+		return scope, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be this, following or all"})
+	return "", false
+}
+
+// @Summary Create a Recurring Reservation
+// @Description Books the same table every week or every month. frequency is weekly or monthly and interval is how many weeks or months apart the bookings are (1 when left out). The series ends after count occurrences or at until, exactly one of them is required, and can have at most 52 occurrences. dateTime and exitTime are the first occurrence, the others keep its time of day in the restaurant's time zone. Monthly series skip months that don't have the day of the first occurrence. Every occurrence is checked like a single booking, the ones that clash are left out and listed in conflicts. For the booking policy's limit of active bookings the whole series counts as one booking.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param series body models.ReservationSeries true "Series Details"
+// @security BearerAuth
+// @Success 201 {object} SeriesResponse "The series with the booked occurrences, and the occurrences that couldn't be booked."
+// @Failure 400 {object} ValidationErrorResponse "Invalid series details, the fields that are wrong are listed."
+// @Failure 403 {object} ErrorResponse "The user already has as many active bookings as the booking policy allows, or is restricted after too many no-shows."
+// @Failure 409 {object} SeriesConflictResponse "None of the occurrences could be booked, what went wrong with each is listed."
+// @Failure 500 {object} ErrorResponse "Internal server error while creating the series."
+// @Router /reservation-series [post]
+func CreateReservationSeries(c *gin.Context) {
+	var series models.ReservationSeries
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+
+	// Admins can book past the limit of active bookings
+	enforceLimit := claims.Role != models.RoleAdmin
+
+	conflicts, err := reservationHandler.CreateSeries(claims.UserId, &series, enforceLimit)
+	if err != nil {
+		var invalid *models.ValidationError
+		var limit *models.BookingLimitError
+		var restricted *models.BookingRestrictedError
+		switch {
+		case errors.As(err, &invalid):
+			validationError(c, err)
+		case errors.As(err, &limit):
+			c.JSON(http.StatusForbidden, gin.H{"error": limit.Error()})
+		case errors.As(err, &restricted):
+			c.JSON(http.StatusForbidden, gin.H{"error": restricted.Error()})
+		case errors.Is(err, models.ErrNothingBooked):
+			c.JSON(http.StatusConflict, SeriesConflictResponse{Error: err.Error(), Conflicts: conflicts})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating reservation series"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, SeriesResponse{Series: series, Conflicts: conflicts})
+}
+
+// @Summary Get a Recurring Reservation
+// @Description Retrieves a series with all of its occurrences, oldest first. Only the guest who booked, the restaurant's staff and admins can see it.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Series ID"
+// @security BearerAuth
+// @Success 200 {object} models.ReservationSeries "The series and its occurrences."
+// @Failure 400 {object} ErrorResponse "Invalid series ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this series."
+// @Failure 404 {object} ErrorResponse "Series not found with the specified ID."
+// @Router /reservation-series/{id} [get]
+func GetReservationSeries(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series id"})
+		return
+	}
+
+	series, err := reservationHandler.GetSeries(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+	if series.UserID != claims.UserId && !middleware.CanAccessRestaurant(claims, middleware.PermRestaurantReservationsRead, series.RestaurantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to see this series"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// Every occurrence is checked on its own, one that was moved to another restaurant has other staff
+func canManageOccurrence(claims *middleware.Claims) func(*models.Reservation) bool {
+	return func(occurrence *models.Reservation) bool {
+		return canAccessReservation(claims, occurrence, middleware.PermRestaurantReservationsManage)
+	}
+}
+
+// Apply an edit to this and the following occurrences, or the whole series
+func updateReservationSeries(c *gin.Context, reservation *models.Reservation, patch *models.Reservation, scope models.SeriesScope) {
+	// A series stays at one restaurant
+	patch.RestaurantID = 0
+
+	claims := middleware.GetClaims(c)
+	result, err := reservationHandler.UpdateSeries(reservation, patch, scope, claims.UserId, canManageOccurrence(claims))
+	if err != nil {
+		if errors.Is(err, models.ErrNotInSeries) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		abortBookingError(c, err, "Error updating reservation series")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Cancel this and the following occurrences, or the whole series
+func cancelReservationSeries(c *gin.Context, scope models.SeriesScope) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation id"})
+		return
+	}
+
+	reservation, err := reservationHandler.GetReservation(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+	if !canAccessReservation(claims, reservation, middleware.PermRestaurantReservationsManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to change the status of this reservation"})
+		return
+	}
+
+	result, err := reservationHandler.CancelSeries(reservation, scope, claims.UserId, canManageOccurrence(claims))
+	if err != nil {
+		if errors.Is(err, models.ErrNotInSeries) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error cancelling reservation series"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,186 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"gorm.io/gorm"
+)
+
+// Move the reservation in the url to a new status. The restaurant's staff and admins can do
+// every transition, the guest who booked only when guestAllowed is set.
+func transitionReservation(c *gin.Context, to models.ReservationStatus, guestAllowed bool) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation id"})
+		return
+	}
+
+	reservation, err := reservationHandler.GetReservation(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	changeReservationStatus(c, reservation, to, guestAllowed)
+}
+
+func changeReservationStatus(c *gin.Context, reservation *models.Reservation, to models.ReservationStatus, guestAllowed bool) {
+	claims := middleware.GetClaims(c)
+	isGuest := guestAllowed && reservation.UserID == claims.UserId
+	if !isGuest && !middleware.CanAccessRestaurant(claims, middleware.PermRestaurantReservationsManage, reservation.RestaurantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to change the status of this reservation"})
+		return
+	}
+
+	reservation, err := reservationHandler.TransitionReservation(reservation.ID, to, claims.UserId)
+	if err != nil {
+		var invalid *models.InvalidTransitionError
+		var tooLate *models.CancellationWindowError
+		switch {
+		case errors.As(err, &invalid):
+			c.JSON(http.StatusConflict, gin.H{"error": invalid.Error()})
+		case errors.As(err, &tooLate):
+			c.JSON(http.StatusForbidden, gin.H{"error": tooLate.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating reservation status"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+// @Summary Confirm a Reservation
+// @Description Moves a pending reservation to confirmed. Only the restaurant's staff and admins can confirm.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation with its new status."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to change the status of this reservation."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The reservation can't be confirmed from its current status."
+// @Router /reservations/{id}/confirm [post]
+func ConfirmReservation(c *gin.Context) {
+	transitionReservation(c, models.StatusConfirmed, false)
+}
+
+// @Summary Seat a Reservation
+// @Description Marks a confirmed reservation as seated when the guest arrives. Only the restaurant's staff and admins can seat guests.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation with its new status."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to change the status of this reservation."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The reservation can't be seated from its current status."
+// @Router /reservations/{id}/seat [post]
+func SeatReservation(c *gin.Context) {
+	transitionReservation(c, models.StatusSeated, false)
+}
+
+// @Summary Complete a Reservation
+// @Description Marks a seated reservation as completed when the guest leaves, which frees the table. Only the restaurant's staff and admins can complete reservations.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation with its new status."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to change the status of this reservation."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The reservation can't be completed from its current status."
+// @Router /reservations/{id}/complete [post]
+func CompleteReservation(c *gin.Context) {
+	transitionReservation(c, models.StatusCompleted, false)
+}
+
+// @Summary Cancel a Reservation
+// @Description Cancels a pending or confirmed reservation and frees its table. The guest who booked, the restaurant's staff and admins can cancel. Guests can only cancel until the cancellation deadline of the restaurant's booking policy. For a recurring reservation, scope=following also cancels the later occurrences and scope=all every occurrence that hasn't happened yet, occurrences that can't be cancelled are listed in conflicts.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID" Format(int64)
+// @Param scope query string false "this (default), following or all"
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation with its new status. With scope following or all a models.SeriesChangeResult."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID format or scope, or the reservation is not part of a series."
+// @Failure 403 {object} ErrorResponse "Not allowed to change the status of this reservation, or the cancellation deadline has passed."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The reservation can't be cancelled from its current status."
+// @Router /reservations/{id}/cancel [post]
+func CancelReservation(c *gin.Context) {
+	scope, ok := seriesScope(c)
+	if !ok {
+		return
+	}
+	if scope != models.ScopeThis {
+		cancelReservationSeries(c, scope)
+		return
+	}
+	transitionReservation(c, models.StatusCancelled, true)
+}
+
+// @Summary Mark a Reservation as No-Show
+// @Description Marks a confirmed reservation whose guest never arrived as a no-show, which frees the table and adds to the guest's no-show count. Guests with too many recent no-shows can't book, see the booking policy. Only the restaurant's staff and admins can do this.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.Reservation "The reservation with its new status."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to change the status of this reservation."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The reservation can't be marked as a no-show from its current status."
+// @Router /reservations/{id}/no-show [post]
+func NoShowReservation(c *gin.Context) {
+	transitionReservation(c, models.StatusNoShow, false)
+}
+
+// @Summary Get Reservation Status Changes
+// @Description Lists every status change of a reservation with who made it and when. Only the guest who booked, the restaurant's staff and admins can see it.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {array} models.ReservationStatusChange "The status changes, oldest first."
+// @Failure 400 {object} ErrorResponse "Invalid reservation ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this reservation."
+// @Failure 404 {object} ErrorResponse "Reservation not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching the status changes."
+// @Router /reservations/{id}/status-changes [get]
+func GetReservationStatusChanges(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation id"})
+		return
+	}
+
+	reservation, err := reservationHandler.GetReservation(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	if !canAccessReservation(middleware.GetClaims(c), reservation, middleware.PermRestaurantReservationsRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to see this reservation"})
+		return
+	}
+
+	changes, err := reservationHandler.GetStatusChanges(reservation.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching status changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
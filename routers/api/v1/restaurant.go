@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
 	"github.com/punchanabu/redrice-backend-go/models"
 	"github.com/punchanabu/redrice-backend-go/utils"
 	"gorm.io/gorm"
@@ -16,8 +18,18 @@ func InitializedRestaurantHandler(db *gorm.DB) {
 	RestaurantHandler = models.NewRestaurantHandler(db)
 }
 
+// Reply with the fields that failed validation, or a plain 400 for other errors
+func validationError(c *gin.Context, err error) {
+	var invalid *models.ValidationError
+	if errors.As(err, &invalid) {
+		c.JSON(http.StatusBadRequest, ValidationErrorResponse{Error: invalid.Error(), Fields: invalid.Fields})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
 // @Summary Get a Single Restaurant
-// @Description Retrieves details of a single restaurant by its unique identifier.
+// @Description Retrieves details of a single restaurant by its unique identifier, with its weekly schedule, upcoming opening exceptions and whether it is open right now in its own time zone.
 // @Tags restaurants
 // @Produce json
 // @Param id path int true "Restaurant ID" Format(int64)
@@ -63,14 +75,14 @@ func GetRestaurants(c *gin.Context) {
 }
 
 // @Summary Create a New Restaurant
-// @Description Adds a new restaurant to the system with the provided details.
+// @Description Adds a new restaurant to the system with the provided details. openTime and closeTime are times of day like 18:00, a closeTime before the openTime means the restaurant closes after midnight.
 // @Tags restaurants
 // @Accept json
 // @Produce json
 // @Param restaurant body models.Restaurant true "Restaurant Registration Details"
 // @security BearerAuth
 // @Success 201 {object} models.Restaurant "The created restaurant's details, including its unique identifier."
-// @Failure 400 {object} ErrorResponse "Invalid input format for restaurant details."
+// @Failure 400 {object} ValidationErrorResponse "Invalid input format for restaurant details, invalid opening hours are listed per field."
 // @Failure 500 {object} ErrorResponse "Internal server error while creating the restaurant."
 // @Router /restaurants [post]
 func CreateRestaurant(c *gin.Context) {
@@ -87,8 +99,20 @@ func CreateRestaurant(c *gin.Context) {
 	description := c.Request.FormValue("description")
 	facebook := c.Request.FormValue("facebook")
 	instagram := c.Request.FormValue("instagram")
-	openTime := c.Request.FormValue("openTime")
-	closeTime := c.Request.FormValue("closeTime")
+	openTime, closeTime, err := models.NormalizeOpeningHours(c.Request.FormValue("openTime"), c.Request.FormValue("closeTime"))
+	if err != nil {
+		validationError(c, err)
+		return
+	}
+
+	timeZone := c.Request.FormValue("timeZone")
+	if timeZone != "" {
+		if err := models.ValidateTimeZone(timeZone); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
 	file, header, err := c.Request.FormFile("image")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Error parsing image!"})
@@ -114,6 +138,7 @@ func CreateRestaurant(c *gin.Context) {
 		Instagram:   instagram,
 		OpenTime:    openTime,
 		CloseTime:   closeTime,
+		TimeZone:    timeZone,
 	}
 
 	if err := RestaurantHandler.CreateRestaurant(&restaurant); err != nil {
@@ -125,7 +150,7 @@ func CreateRestaurant(c *gin.Context) {
 }
 
 // @Summary Update a Restaurant
-// @Description Updates the details of an existing restaurant identified by its ID.
+// @Description Updates the details of an existing restaurant identified by its ID. Restaurant owners can update their own restaurant, only admins can change the rating and comment count.
 // @Tags restaurants
 // @Accept json
 // @Produce json
@@ -133,7 +158,7 @@ func CreateRestaurant(c *gin.Context) {
 // @Param restaurant body models.Restaurant true "Updated Restaurant Details"
 // @security BearerAuth
 // @Success 200 {object} models.Restaurant "The updated restaurant's details."
-// @Failure 400 {object} ErrorResponse "Invalid input format for restaurant details or invalid restaurant ID."
+// @Failure 400 {object} ValidationErrorResponse "Invalid input format for restaurant details or invalid restaurant ID, invalid opening hours are listed per field."
 // @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
 // @Router /restaurants/{id} [put]
 func UpdateRestaurant(c *gin.Context) {
@@ -160,9 +185,38 @@ func UpdateRestaurant(c *gin.Context) {
 	instagram := c.Request.FormValue("instagram")
 	openTime := c.Request.FormValue("openTime")
 	closeTime := c.Request.FormValue("closeTime")
+	timeZone := c.Request.FormValue("timeZone")
 	ratingStr := c.Request.FormValue("rating")
 	commentCountStr := c.Request.FormValue("commentCount")
 
+	if timeZone != "" {
+		if err := models.ValidateTimeZone(timeZone); err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
+	// Opening hours are checked as a pair, so fill in the one that isn't changing
+	if openTime != "" || closeTime != "" {
+		current, err := RestaurantHandler.GetRestaurant(idUint)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+			return
+		}
+		if openTime == "" {
+			openTime = current.OpenTime
+		}
+		if closeTime == "" {
+			closeTime = current.CloseTime
+		}
+
+		openTime, closeTime, err = models.NormalizeOpeningHours(openTime, closeTime)
+		if err != nil {
+			validationError(c, err)
+			return
+		}
+	}
+
 	file, header, err := c.Request.FormFile("image")
 	var imageUrl string
 	if err == nil {
@@ -186,11 +240,18 @@ func UpdateRestaurant(c *gin.Context) {
 		Instagram:   instagram,
 		OpenTime:    openTime,
 		CloseTime:   closeTime,
+		TimeZone:    timeZone,
 	}
 	if imageUrl != "" {
 		updatedRestaurant.ImageURL = imageUrl
 	}
 
+	claims := middleware.GetClaims(c)
+	if (ratingStr != "" || commentCountStr != "") && !middleware.HasGlobalAccess(claims, middleware.PermRestaurantRatingsUpdate) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change the rating of a restaurant"})
+		return
+	}
+
 	if ratingStr != "" {
 		rating, err := strconv.ParseFloat(ratingStr, 64)
 		if err != nil {
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"gorm.io/gorm"
+)
+
+var tableHandler *models.TableHandler
+
+func InitializedTableHandler(db *gorm.DB) {
+	tableHandler = models.NewTableHandler(db)
+}
+
+type TableRequest struct {
+	Number int `json:"number" example:"4"`
+	Seats  int `json:"seats" example:"2"`
+}
+
+// Load the table and make sure it belongs to the restaurant in the url
+func restaurantTable(c *gin.Context) (*models.Table, bool) {
+	restaurantID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return nil, false
+	}
+
+	tableID, err := strconv.Atoi(c.Param("tableId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid table id"})
+		return nil, false
+	}
+
+	table, err := tableHandler.GetTable(uint(tableID))
+	if err != nil || table.RestaurantID != uint(restaurantID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+		return nil, false
+	}
+
+	return table, true
+}
+
+// @Summary Get Restaurant Tables
+// @Description Lists the tables of a restaurant with their seat counts.
+// @Tags tables
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {array} models.Table "An array of table objects."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID format."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching tables."
+// @Router /restaurants/{id}/tables [get]
+func GetRestaurantTables(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	tables, err := tableHandler.GetTablesByRestaurantID(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tables!"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tables)
+}
+
+// @Summary Add a Table
+// @Description Adds a table to the restaurant. Table numbers are unique per restaurant. Only the restaurant's owner and admins can manage tables.
+// @Tags tables
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param table body TableRequest true "Table Details"
+// @security BearerAuth
+// @Success 201 {object} models.Table "The created table."
+// @Failure 400 {object} ErrorResponse "Invalid input format or restaurant ID."
+// @Failure 403 {object} ErrorResponse "Not allowed to manage tables of this restaurant."
+// @Failure 409 {object} ErrorResponse "A table with this number already exists."
+// @Failure 500 {object} ErrorResponse "Internal server error while creating the table."
+// @Router /restaurants/{id}/tables [post]
+func CreateTable(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	var request TableRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Number <= 0 || request.Seats <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please give a table number and a seat count above 0"})
+		return
+	}
+
+	if _, err := restaurantHandler.GetRestaurant(uint(idInt)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		return
+	}
+
+	table := models.Table{
+		RestaurantID: uint(idInt),
+		Number:       request.Number,
+		Seats:        request.Seats,
+	}
+	if err := tableHandler.CreateTable(&table); err != nil {
+		if errors.Is(err, models.ErrTableExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating table"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, table)
+}
+
+// @Summary Update a Table
+// @Description Changes the number or the seat count of a table. Reservations on the table get its new number. The seats can't be lowered below the party size of an upcoming reservation, a seated walk-in or a held waitlist offer on the table. Only the restaurant's owner and admins can manage tables.
+// @Tags tables
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param tableId path int true "Table ID" Format(int64)
+// @Param table body TableRequest true "Updated Table Details"
+// @security BearerAuth
+// @Success 200 {object} models.Table "The updated table."
+// @Failure 400 {object} ErrorResponse "Invalid input format, restaurant ID or table ID."
+// @Failure 403 {object} ErrorResponse "Not allowed to manage tables of this restaurant."
+// @Failure 404 {object} ErrorResponse "Table not found at this restaurant."
+// @Failure 409 {object} ErrorResponse "A table with this number already exists, or upcoming reservations have more guests than the new seat count."
+// @Failure 500 {object} ErrorResponse "Internal server error while updating the table."
+// @Router /restaurants/{id}/tables/{tableId} [put]
+func UpdateTable(c *gin.Context) {
+	var request TableRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Number < 0 || request.Seats < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	table, ok := restaurantTable(c)
+	if !ok {
+		return
+	}
+
+	update := models.Table{
+		RestaurantID: table.RestaurantID,
+		Number:       request.Number,
+		Seats:        request.Seats,
+	}
+	if err := tableHandler.UpdateTable(table.ID, &update); err != nil {
+		if errors.Is(err, models.ErrTableExists) || errors.Is(err, models.ErrTableTooSmallForBookings) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating table"})
+		return
+	}
+
+	table, err := tableHandler.GetTable(table.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching table"})
+		return
+	}
+
+	c.JSON(http.StatusOK, table)
+}
+
+// @Summary Delete a Table
+// @Description Removes a table from the restaurant. Tables with upcoming reservations or seated walk-ins can't be removed until those are moved or cancelled. Only the restaurant's owner and admins can manage tables.
+// @Tags tables
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param tableId path int true "Table ID" Format(int64)
+// @security BearerAuth
+// @Success 204 "Table successfully deleted, no content to return."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID or table ID."
+// @Failure 403 {object} ErrorResponse "Not allowed to manage tables of this restaurant."
+// @Failure 404 {object} ErrorResponse "Table not found at this restaurant."
+// @Failure 409 {object} ErrorResponse "The table still has upcoming reservations."
+// @Failure 500 {object} ErrorResponse "Internal server error while deleting the table."
+// @Router /restaurants/{id}/tables/{tableId} [delete]
+func DeleteTable(c *gin.Context) {
+	table, ok := restaurantTable(c)
+	if !ok {
+		return
+	}
+
+	if err := tableHandler.DeleteTable(table.ID); err != nil {
+		if errors.Is(err, models.ErrTableInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting table"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,31 +1,89 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
 	"github.com/punchanabu/redrice-backend-go/models"
 	"gorm.io/gorm"
 )
 
 var userHandler *models.UserHandler
+var sessionHandler *models.SessionHandler
 
 type ErrorResponse struct {
 	Error string `json:"error" example:"Description of the error occurred"`
 }
 
+type ValidationErrorResponse struct {
+	Error  string            `json:"error" example:"dateTime must be in the future"`
+	Fields map[string]string `json:"fields"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message" example:"Done successfully"`
+}
+
 func InitializedUserHandler(db *gorm.DB) {
 	userHandler = models.NewUserHandler(db)
+	sessionHandler = models.NewSessionHandler(db)
+}
+
+// Fields an admin may set when creating a user
+type CreateUserRequest struct {
+	Name         string `json:"name" example:"John Doe"`
+	Telephone    string `json:"telephone" example:"123-456-7890"`
+	Email        string `json:"email" example:"john.doe@example.com"`
+	Password     string `json:"password" example:"securePassword123"`
+	Role         string `json:"role" example:"restaurant_owner"`
+	RestaurantId uint   `json:"restaurant_id" example:"1"`
+}
+
+// Fields that can be changed on a user, only admins may send role and restaurant_id
+type UpdateUserRequest struct {
+	Name         *string `json:"name" example:"John Doe"`
+	Telephone    *string `json:"telephone" example:"123-456-7890"`
+	Email        *string `json:"email" example:"john.doe@example.com"`
+	AvatarURL    *string `json:"avatarUrl" example:"https://example.com/avatar.png"`
+	Role         *string `json:"role" example:"user"`
+	RestaurantId *uint   `json:"restaurant_id" example:"0"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" example:"securePassword123"`
+	NewPassword     string `json:"newPassword" example:"evenMoreSecurePassword456"`
+}
+
+// Make sure a role and restaurant assignment fit together
+func validateRoleAssignment(role string, restaurantId uint) string {
+	if !models.IsValidRole(role) {
+		return "Invalid role"
+	}
+
+	if models.IsRestaurantRole(role) && restaurantId == 0 {
+		return "Restaurant owners and staff need a restaurant_id"
+	}
+
+	if restaurantId != 0 {
+		if _, err := RestaurantHandler.GetRestaurant(restaurantId); err != nil {
+			return "Invalid restaurant ID"
+		}
+	}
+
+	return ""
 }
 
 // @Summary Get a Single User
-// @Description Retrieves details of a single user by their unique identifier.
+// @Description Retrieves details of a single user by their unique identifier. Other users only see the name and avatar, the user themselves and admins see the full profile.
 // @Tags user
 // @Produce json
 // @Param id path int true "User ID" Format(int64)
 // @security BearerAuth
-// @Success 200 {object} models.User "The details of the user including ID, name, email, telephone, and role."
+// @Success 200 {object} models.OwnerUser "The details of the user including ID, name, email, telephone, and role."
 // @Failure 400 {object} ErrorResponse "Invalid user ID format."
 // @Failure 404 {object} ErrorResponse "User not found with the specified ID."
 // @Router /users/{id} [get]
@@ -47,15 +105,27 @@ func GetUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, userView(c, user))
+}
+
+// Pick how much of a user the caller is allowed to see
+func userView(c *gin.Context, user *models.User) interface{} {
+	claims := middleware.GetClaims(c)
+	if middleware.HasGlobalAccess(claims, middleware.PermUsersManage) {
+		return user.AdminView()
+	}
+	if claims.UserId == user.ID {
+		return user.OwnerView()
+	}
+	return user.PublicView()
 }
 
 // @Summary Get All Users
-// @Description Retrieves a list of all users in the system.
+// @Description Retrieves a list of all users in the system. Admins see the full profiles, everyone else only sees names and avatars.
 // @Tags user
 // @Produce json
 // @security BearerAuth
-// @Success 200 {array} models.User "An array of user objects."
+// @Success 200 {array} models.PublicUser "An array of user objects."
 // @Failure 500 {object} ErrorResponse "Internal server error while fetching users."
 // @Router /users [get]
 func GetUsers(c *gin.Context) {
@@ -66,7 +136,12 @@ func GetUsers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	views := make([]interface{}, 0, len(users))
+	for i := range users {
+		views = append(views, userView(c, &users[i]))
+	}
+
+	c.JSON(http.StatusOK, views)
 }
 
 // @Summary Create a New User
@@ -74,27 +149,56 @@ func GetUsers(c *gin.Context) {
 // @Tags user
 // @Accept json
 // @Produce json
-// @Param user body models.User true "User Registration Details"
+// @Param user body CreateUserRequest true "User Registration Details"
+// @Param verified query bool false "Mark the email as already verified"
 // @security BearerAuth
-// @Success 201 {object} models.User "The created user's details, including their unique identifier."
-// @Failure 400 {object} ErrorResponse "Invalid input format for user details."
+// @Success 201 {object} models.AdminUser "The created user's details, including their unique identifier."
+// @Failure 400 {object} ErrorResponse "Invalid input format for user details, or the password is too short."
 // @Failure 500 {object} ErrorResponse "Internal server error while creating the user."
 // @Router /users [post]
 func CreateUser(c *gin.Context) {
-	var user models.User
+	var details CreateUserRequest
 
-	if err := c.ShouldBindJSON(&user); err != nil {
+	if err := c.ShouldBindJSON(&details); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	if len(details.Password) < models.MinPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", models.MinPasswordLength)})
+		return
+	}
+
+	if details.Role == "" {
+		details.Role = models.RoleUser
+	}
+
+	if msg := validateRoleAssignment(details.Role, details.RestaurantId); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	user := models.User{
+		Name:         details.Name,
+		Telephone:    details.Telephone,
+		Email:        details.Email,
+		Password:     details.Password,
+		Role:         details.Role,
+		RestaurantId: details.RestaurantId,
+	}
+
+	// Admins can vouch for the email of accounts they create
+	if verified, _ := strconv.ParseBool(c.Query("verified")); verified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
 	// Check if email or telephone already exists
-	existingUser, err := userHandler.GetUserByEmail(user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking for duplicate user"})
+	if existingUser, _ := userHandler.GetUserByEmail(user.Email); existingUser != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email or telephone already exists"})
 		return
 	}
-	if existingUser != nil {
+	if existingUser, _ := userHandler.GetUserByTelephone(user.Telephone); existingUser != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email or telephone already exists"})
 		return
 	}
@@ -104,19 +208,21 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, user)
+	c.JSON(http.StatusCreated, user.AdminView())
 }
 
 // @Summary Update a User
-// @Description Updates the details of an existing user identified by their ID.
+// @Description Updates the details of an existing user identified by their ID. Users can change their own name, email, telephone and avatar. Only admins can update other users or change a role or restaurant assignment.
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID" Format(int64)
-// @Param user body models.User true "Updated User Details"
+// @Param user body UpdateUserRequest true "Updated User Details"
 // @security BearerAuth
-// @Success 200 {object} models.User "The updated user's details."
+// @Success 200 {object} models.OwnerUser "The updated user's details."
 // @Failure 400 {object} ErrorResponse "Invalid input format for user details or invalid user ID."
+// @Failure 403 {object} ErrorResponse "Not allowed to update this user or these fields."
+// @Failure 409 {object} ErrorResponse "Email or telephone already belongs to another user."
 // @Failure 500 {object} ErrorResponse "Internal server error while updating the user."
 // @Router /users/{id} [put]
 func UpdateUser(c *gin.Context) {
@@ -128,20 +234,109 @@ func UpdateUser(c *gin.Context) {
 	}
 	idUint := uint(idInt)
 
-	var user models.User
+	claims := middleware.GetClaims(c)
+	isAdmin := middleware.HasGlobalAccess(claims, middleware.PermUsersManage)
+
+	if !isAdmin && claims.UserId != idUint {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own profile"})
+		return
+	}
+
+	var details UpdateUserRequest
 
-	if err := c.ShouldBindJSON(&user); err != nil {
+	if err := c.ShouldBindJSON(&details); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = userHandler.UpdateUser(idUint, &user)
+	if !isAdmin && (details.Role != nil || details.RestaurantId != nil) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change roles or restaurant assignments"})
+		return
+	}
+
+	user, err := userHandler.GetUser(idUint)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating user"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	fields := map[string]interface{}{}
+
+	if details.Name != nil {
+		fields["name"] = *details.Name
+	}
+
+	if details.AvatarURL != nil {
+		fields["avatar_url"] = *details.AvatarURL
+	}
+
+	if details.Telephone != nil && *details.Telephone != user.Telephone {
+		if existingUser, _ := userHandler.GetUserByTelephone(*details.Telephone); existingUser != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email or telephone already exists"})
+			return
+		}
+		fields["telephone"] = *details.Telephone
+	}
+
+	if details.Email != nil && *details.Email != user.Email {
+		if existingUser, _ := userHandler.GetUserByEmail(*details.Email); existingUser != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email or telephone already exists"})
+			return
+		}
+		fields["email"] = *details.Email
+
+		// A new address has to be verified again, unless an admin changed it
+		if !isAdmin {
+			fields["email_verified_at"] = nil
+		}
+	}
+
+	roleChanged := false
+	if details.Role != nil || details.RestaurantId != nil {
+		role, restaurantId := user.Role, user.RestaurantId
+		if details.Role != nil {
+			role = *details.Role
+		}
+		if details.RestaurantId != nil {
+			restaurantId = *details.RestaurantId
+		}
+
+		if msg := validateRoleAssignment(role, restaurantId); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		roleChanged = role != user.Role || restaurantId != user.RestaurantId
+		fields["role"] = role
+		fields["restaurant_id"] = restaurantId
+	}
+
+	if len(fields) > 0 {
+		update := userHandler.UpdateUserFields
+		if _, ok := fields["email"]; ok {
+			update = userHandler.UpdateUserEmailFields
+		}
+		if err := update(idUint, fields); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating user"})
+			return
+		}
+	}
+
+	// Old tokens still carry the old role, so log the user out everywhere
+	if roleChanged {
+		if err := sessionHandler.RevokeUserSessions(idUint); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error revoking sessions"})
+			return
+		}
+	}
+
+	updatedUser, err := userHandler.GetUser(idUint)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, userView(c, updatedUser))
 }
 
 // @Summary Delete a User
@@ -177,7 +372,7 @@ func DeleteUser(c *gin.Context) {
 // @Tags user
 // @Produce json
 // @security BearerAuth
-// @Success 200 {object} models.User "The details of the currently authenticated user."
+// @Success 200 {object} models.OwnerUser "The details of the currently authenticated user."
 // @Failure 404 {object} ErrorResponse "User not found."
 // @Router /me [get]
 func GetMe(c *gin.Context) {
@@ -187,5 +382,55 @@ func GetMe(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, user.OwnerView())
+}
+
+// @Summary Change my password
+// @Description Changes the password of the currently authenticated user. The current password is required, and every other session is logged out.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param body body ChangePasswordRequest true "Current and New Password"
+// @security BearerAuth
+// @Success 200 {object} MessageResponse "The password has been changed."
+// @Failure 400 {object} ErrorResponse "Invalid input or the new password is too short."
+// @Failure 401 {object} ErrorResponse "The current password is incorrect."
+// @Failure 500 {object} ErrorResponse "Internal server error while changing the password."
+// @Router /me/password [put]
+func ChangePassword(c *gin.Context) {
+	var details ChangePasswordRequest
+	if err := c.ShouldBindJSON(&details); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(details.NewPassword) < models.MinPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", models.MinPasswordLength)})
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+
+	user, err := userHandler.GetUser(claims.UserId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !userHandler.CheckPassword(user.Email, details.CurrentPassword) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect!"})
+		return
+	}
+
+	if err := userHandler.UpdatePassword(user.ID, details.NewPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error changing password"})
+		return
+	}
+
+	if err := sessionHandler.RevokeOtherSessions(user.ID, claims.SessionId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error revoking sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
 }
@@ -0,0 +1,289 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"github.com/punchanabu/redrice-backend-go/utils"
+	"gorm.io/gorm"
+)
+
+var waitlistHandler *models.WaitlistHandler
+var waitlistMailer utils.Mailer = &utils.LogMailer{}
+
+func InitializedWaitlistHandler(db *gorm.DB) {
+	waitlistHandler = models.NewWaitlistHandler(db)
+}
+
+// Replace the sender of offer mails, e.g. with the one the auth mails go through
+func SetWaitlistMailer(m utils.Mailer) {
+	waitlistMailer = m
+}
+
+type WaitlistRequest struct {
+	DateTime  time.Time `json:"dateTime" example:"2024-05-01T18:00:00+07:00"`
+	ExitTime  time.Time `json:"exitTime" example:"2024-05-01T20:00:00+07:00"`
+	PartySize int       `json:"partySize" example:"2"`
+}
+
+// Expire waitlist offers nobody confirmed in time, until ctx is done
+func ExpireWaitlistOffers(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := waitlistHandler.ExpireOffers(); err != nil {
+				log.Println("Error expiring waitlist offers:", err)
+			}
+		}
+	}
+}
+
+// Mail guests who got a table held for them, until ctx is done. Offers are made inside booking
+// transactions, so they are picked up from the database once those committed.
+func NotifyWaitlistOffers(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			entries, err := waitlistHandler.GetUnnotifiedOffers()
+			if err != nil {
+				log.Println("Error fetching waitlist offers:", err)
+				continue
+			}
+			for _, entry := range entries {
+				if err := sendWaitlistOffer(&entry); err != nil {
+					log.Println("Error sending waitlist offer mail:", err)
+					continue
+				}
+				if err := waitlistHandler.MarkNotified(entry.ID); err != nil {
+					log.Println("Error marking waitlist offer as sent:", err)
+				}
+			}
+		}
+	}
+}
+
+func sendWaitlistOffer(entry *models.WaitlistEntry) error {
+	loc := entry.Restaurant.Location()
+	link := fmt.Sprintf("%s/waitlist/%d", os.Getenv("APP_URL"), entry.ID)
+	body := fmt.Sprintf(
+		"Hi %s,\n\nA table for %d opened up at %s on %s. We are holding table %d for you until %s, confirm it here before then:\n\n%s\n\nIf you don't need it anymore, you can ignore this email and it goes to the next guest in line.",
+		entry.User.Name, entry.PartySize, entry.Restaurant.Name,
+		entry.DateTime.In(loc).Format("Mon 2 Jan 15:04"), entry.TableNum,
+		entry.HoldExpiresAt.In(loc).Format("15:04"), link,
+	)
+	return waitlistMailer.Send(entry.User.Email, "A table opened up at "+entry.Restaurant.Name, body)
+}
+
+func waitlistEntryParam(c *gin.Context) (*models.WaitlistEntry, bool) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waitlist id"})
+		return nil, false
+	}
+
+	entry, err := waitlistHandler.GetEntry(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// @Summary Join a Restaurant's Waitlist
+// @Description Puts the current user on the waitlist for a time at a fully booked restaurant. When a booking that overlaps the time is cancelled or deleted, the first guest in line that fits gets a table held for them, is sent an email about it and has to confirm it at /waitlist/{id}/confirm before the hold runs out. A guest can only wait once for overlapping times at a restaurant.
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param entry body WaitlistRequest true "Wanted Time and Party Size"
+// @security BearerAuth
+// @Success 201 {object} models.WaitlistEntry "The waitlist entry with its position in the queue."
+// @Failure 400 {object} ValidationErrorResponse "Invalid input format, the fields that are wrong are listed."
+// @Failure 403 {object} ErrorResponse "The email address has not been verified yet."
+// @Failure 409 {object} ErrorResponse "A table is still free for this time, or the user is already waiting for an overlapping time."
+// @Failure 500 {object} ErrorResponse "Internal server error while joining the waitlist."
+// @Router /restaurants/{id}/waitlist [post]
+func JoinWaitlist(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	var request WaitlistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+	if request.PartySize <= 0 {
+		validationError(c, &models.ValidationError{Fields: map[string]string{"partySize": "must be above 0"}})
+		return
+	}
+
+	entry := models.WaitlistEntry{
+		RestaurantID: uint(idInt),
+		DateTime:     request.DateTime,
+		ExitTime:     request.ExitTime,
+		PartySize:    request.PartySize,
+	}
+	if err := waitlistHandler.JoinWaitlist(middleware.GetClaims(c).UserId, &entry); err != nil {
+		var invalid *models.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			validationError(c, err)
+		case errors.Is(err, models.ErrTableFree), errors.Is(err, models.ErrAlreadyOnWaitlist):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, models.ErrNoTables):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining waitlist"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// @Summary Get My Waitlist Entries
+// @Description Lists the waitlist entries of the current user that are still waiting or have a table on hold, with their position in the queue.
+// @Tags waitlist
+// @Produce json
+// @security BearerAuth
+// @Success 200 {array} models.WaitlistEntry "The user's waitlist entries."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching the waitlist."
+// @Router /waitlist [get]
+func GetMyWaitlist(c *gin.Context) {
+	entries, err := waitlistHandler.GetEntriesByUserID(middleware.GetClaims(c).UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// @Summary Get a Restaurant's Waitlist
+// @Description Lists everyone waiting for a table at the restaurant, in the order tables get offered. Only available to admins and the restaurant's owner and staff.
+// @Tags waitlist
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {array} models.WaitlistEntry "The restaurant's waitlist."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this restaurant's waitlist."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching the waitlist."
+// @Router /restaurants/{id}/waitlist [get]
+func GetRestaurantWaitlist(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	entries, err := waitlistHandler.GetEntriesByRestaurantID(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// @Summary Confirm a Waitlist Offer
+// @Description Books the table that is held for the waitlist entry. Only the guest on the waitlist can confirm, and only before the hold runs out. The booking is checked against the current opening hours and booking policy like any other.
+// @Tags waitlist
+// @Produce json
+// @Param id path int true "Waitlist Entry ID" Format(int64)
+// @security BearerAuth
+// @Success 201 {object} models.Reservation "The new reservation."
+// @Failure 400 {object} ValidationErrorResponse "Invalid waitlist ID format, or the booking is no longer within the opening hours or the booking policy."
+// @Failure 403 {object} ErrorResponse "Not your waitlist entry, you already have as many active reservations as the booking policy allows, or you are restricted after too many no-shows."
+// @Failure 404 {object} ErrorResponse "Waitlist entry not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "There is no table on hold for this entry."
+// @Failure 410 {object} ErrorResponse "The hold has run out."
+// @Failure 500 {object} ErrorResponse "Internal server error while booking."
+// @Router /waitlist/{id}/confirm [post]
+func ConfirmWaitlistOffer(c *gin.Context) {
+	entry, ok := waitlistEntryParam(c)
+	if !ok {
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+	if entry.UserID != claims.UserId {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to confirm this waitlist entry"})
+		return
+	}
+
+	reservation, err := waitlistHandler.ConfirmOffer(entry.ID, claims.Role != models.RoleAdmin)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNoOffer):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, models.ErrOfferExpired):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			abortBookingError(c, err, "Error booking the held table")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, reservation)
+}
+
+// @Summary Leave a Waitlist
+// @Description Takes the entry off the waitlist. A table held for it goes to the next guest in line. The guest, the restaurant's staff and admins can do this.
+// @Tags waitlist
+// @Produce json
+// @Param id path int true "Waitlist Entry ID" Format(int64)
+// @security BearerAuth
+// @Success 204 "Left the waitlist, no content to return."
+// @Failure 400 {object} ErrorResponse "Invalid waitlist ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to remove this waitlist entry."
+// @Failure 404 {object} ErrorResponse "Waitlist entry not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The entry is no longer on the waitlist."
+// @Failure 500 {object} ErrorResponse "Internal server error while leaving the waitlist."
+// @Router /waitlist/{id} [delete]
+func LeaveWaitlist(c *gin.Context) {
+	entry, ok := waitlistEntryParam(c)
+	if !ok {
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+	if entry.UserID != claims.UserId && !middleware.CanAccessRestaurant(claims, middleware.PermRestaurantReservationsManage, entry.RestaurantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to remove this waitlist entry"})
+		return
+	}
+
+	if err := waitlistHandler.LeaveWaitlist(entry.ID); err != nil {
+		if errors.Is(err, models.ErrNotWaiting) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error leaving waitlist"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
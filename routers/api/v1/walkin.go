@@ -0,0 +1,301 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/middleware"
+	"github.com/punchanabu/redrice-backend-go/models"
+	"gorm.io/gorm"
+)
+
+var walkInHandler *models.WalkInHandler
+
+func InitializedWalkInHandler(db *gorm.DB) {
+	walkInHandler = models.NewWalkInHandler(db)
+}
+
+type JoinQueueRequest struct {
+	PartySize int    `json:"partySize" example:"2"`
+	Name      string `json:"name" example:"Somchai"`
+}
+
+type SeatWalkInRequest struct {
+	TableNum int `json:"tableNum" example:"4"`
+}
+
+func walkInParam(c *gin.Context) (*models.WalkIn, bool) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid queue id"})
+		return nil, false
+	}
+
+	walkIn, err := walkInHandler.GetWalkIn(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Queue ticket not found"})
+		return nil, false
+	}
+
+	return walkIn, true
+}
+
+func isWalkInGuest(claims *middleware.Claims, walkIn *models.WalkIn) bool {
+	return walkIn.UserID != nil && *walkIn.UserID == claims.UserId
+}
+
+// Move the walk-in in the url along the queue, only the restaurant's staff and admins can,
+// except for leaving which the guest can do too
+func transitionWalkIn(c *gin.Context, to models.WalkInStatus, tableNum int) {
+	walkIn, ok := walkInParam(c)
+	if !ok {
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+	isGuest := to == models.WalkInLeft && isWalkInGuest(claims, walkIn)
+	if !isGuest && !middleware.CanAccessRestaurant(claims, middleware.PermRestaurantReservationsManage, walkIn.RestaurantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to change this queue ticket"})
+		return
+	}
+
+	walkIn, err := walkInHandler.TransitionWalkIn(walkIn.ID, to, tableNum)
+	if err != nil {
+		var invalid *models.InvalidWalkInTransitionError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusConflict, gin.H{"error": invalid.Error()})
+			return
+		}
+		abortBookingError(c, err, "Error updating queue ticket")
+		return
+	}
+
+	c.JSON(http.StatusOK, walkIn)
+}
+
+// @Summary Join a Restaurant's Walk-in Queue
+// @Description Takes a ticket in today's queue for the next free table, from the guest's phone or at the door. The restaurant's staff can add a guest without an account by giving their name. The ticket comes with the position in the queue and an estimated wait, worked out from how long tables usually take to turn over and the bookings already on them.
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @Param body body JoinQueueRequest true "Party Size, and a Name when staff add a guest"
+// @security BearerAuth
+// @Success 201 {object} models.WalkIn "The queue ticket."
+// @Failure 400 {object} ValidationErrorResponse "Invalid input, the restaurant is closed, or it has no table for the party."
+// @Failure 403 {object} ErrorResponse "The email address has not been verified yet."
+// @Failure 409 {object} ErrorResponse "Already in the queue of this restaurant."
+// @Failure 500 {object} ErrorResponse "Internal server error while joining the queue."
+// @Router /restaurants/{id}/queue [post]
+func JoinQueue(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	var request JoinQueueRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	walkIn := models.WalkIn{
+		RestaurantID: uint(idInt),
+		PartySize:    request.PartySize,
+		Name:         request.Name,
+	}
+
+	// Staff adding somebody at the door give a name, everyone else queues themselves
+	claims := middleware.GetClaims(c)
+	if request.Name == "" || !middleware.CanAccessRestaurant(claims, middleware.PermRestaurantReservationsManage, walkIn.RestaurantID) {
+		walkIn.UserID = &claims.UserId
+	}
+
+	if err := walkInHandler.JoinQueue(&walkIn); err != nil {
+		var invalid *models.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			validationError(c, err)
+		case errors.Is(err, models.ErrNoFittingTable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, models.ErrAlreadyQueued):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error joining queue"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, walkIn)
+}
+
+// @Summary Get a Restaurant's Walk-in Queue
+// @Description Lists today's queue of the restaurant in ticket order with the estimated waits, followed by the walk-ins who are seated right now. Only available to admins and the restaurant's owner and staff.
+// @Tags queue
+// @Produce json
+// @Param id path int true "Restaurant ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {array} models.WalkIn "The restaurant's queue."
+// @Failure 400 {object} ErrorResponse "Invalid restaurant ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this restaurant's queue."
+// @Failure 404 {object} ErrorResponse "Restaurant not found with the specified ID."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching the queue."
+// @Router /restaurants/{id}/queue [get]
+func GetRestaurantQueue(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restaurant id"})
+		return
+	}
+
+	restaurant, err := RestaurantHandler.GetRestaurant(uint(idInt))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Restaurant not found"})
+		return
+	}
+
+	walkIns, err := walkInHandler.GetQueue(restaurant)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, walkIns)
+}
+
+// @Summary Get My Queue Tickets
+// @Description Lists the current user's tickets that are still in a queue, with their position and estimated wait.
+// @Tags queue
+// @Produce json
+// @security BearerAuth
+// @Success 200 {array} models.WalkIn "The user's queue tickets."
+// @Failure 500 {object} ErrorResponse "Internal server error while fetching the queue."
+// @Router /queue [get]
+func GetMyQueue(c *gin.Context) {
+	walkIns, err := walkInHandler.GetWalkInsByUserID(middleware.GetClaims(c).UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, walkIns)
+}
+
+// @Summary Get a Queue Ticket
+// @Description Returns the ticket with its current position and estimated wait, for guests to poll while they wait. The guest, the restaurant's staff and admins can see it.
+// @Tags queue
+// @Produce json
+// @Param id path int true "Queue Ticket ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.WalkIn "The queue ticket."
+// @Failure 400 {object} ErrorResponse "Invalid queue ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to see this ticket."
+// @Failure 404 {object} ErrorResponse "Queue ticket not found with the specified ID."
+// @Router /queue/{id} [get]
+func GetQueueTicket(c *gin.Context) {
+	walkIn, ok := walkInParam(c)
+	if !ok {
+		return
+	}
+
+	claims := middleware.GetClaims(c)
+	if !isWalkInGuest(claims, walkIn) && !middleware.CanAccessRestaurant(claims, middleware.PermRestaurantReservationsRead, walkIn.RestaurantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to see this queue ticket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, walkIn)
+}
+
+// @Summary Call a Walk-in
+// @Description Lets the guest know their table is nearly ready. Only the restaurant's staff and admins can call guests.
+// @Tags queue
+// @Produce json
+// @Param id path int true "Queue Ticket ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.WalkIn "The queue ticket, now called."
+// @Failure 400 {object} ErrorResponse "Invalid queue ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to change this ticket."
+// @Failure 404 {object} ErrorResponse "Queue ticket not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The ticket can't be called from its current status."
+// @Router /queue/{id}/call [post]
+func CallWalkIn(c *gin.Context) {
+	transitionWalkIn(c, models.WalkInCalled, 0)
+}
+
+// @Summary Seat a Walk-in
+// @Description Seats the guest on the given table, or on the smallest free table that fits the party when tableNum is left out. Walk-ins only get a table that has no booking for the next 2 hours. The table is kept for them for those 2 hours, or until they are marked finished if that's sooner, after that it can be booked again. Only the restaurant's staff and admins can seat guests.
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Param id path int true "Queue Ticket ID" Format(int64)
+// @Param body body SeatWalkInRequest false "Table to Seat the Guest at"
+// @security BearerAuth
+// @Success 200 {object} models.WalkIn "The queue ticket, now seated."
+// @Failure 400 {object} ValidationErrorResponse "Invalid queue ID, or the table doesn't exist or is too small."
+// @Failure 403 {object} ErrorResponse "Not allowed to change this ticket."
+// @Failure 404 {object} ErrorResponse "Queue ticket not found with the specified ID."
+// @Failure 409 {object} ConflictResponse "The ticket can't be seated from its current status, or no table is free, the booking in the way is returned."
+// @Router /queue/{id}/seat [post]
+func SeatWalkIn(c *gin.Context) {
+	var request SeatWalkInRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+			return
+		}
+	}
+
+	transitionWalkIn(c, models.WalkInSeated, request.TableNum)
+}
+
+// @Summary Skip a Walk-in
+// @Description Takes a guest who didn't answer the call out of the queue. Only the restaurant's staff and admins can skip guests.
+// @Tags queue
+// @Produce json
+// @Param id path int true "Queue Ticket ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.WalkIn "The queue ticket, now skipped."
+// @Failure 400 {object} ErrorResponse "Invalid queue ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to change this ticket."
+// @Failure 404 {object} ErrorResponse "Queue ticket not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The ticket can't be skipped from its current status."
+// @Router /queue/{id}/skip [post]
+func SkipWalkIn(c *gin.Context) {
+	transitionWalkIn(c, models.WalkInSkipped, 0)
+}
+
+// @Summary Finish a Walk-in
+// @Description Marks a seated walk-in as gone, so their table can be booked again. Only the restaurant's staff and admins can do this.
+// @Tags queue
+// @Produce json
+// @Param id path int true "Queue Ticket ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.WalkIn "The queue ticket, now finished."
+// @Failure 400 {object} ErrorResponse "Invalid queue ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to change this ticket."
+// @Failure 404 {object} ErrorResponse "Queue ticket not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The walk-in isn't seated."
+// @Router /queue/{id}/finish [post]
+func FinishWalkIn(c *gin.Context) {
+	transitionWalkIn(c, models.WalkInFinished, 0)
+}
+
+// @Summary Leave a Walk-in Queue
+// @Description Takes the ticket out of the queue. The guest, the restaurant's staff and admins can do this.
+// @Tags queue
+// @Produce json
+// @Param id path int true "Queue Ticket ID" Format(int64)
+// @security BearerAuth
+// @Success 200 {object} models.WalkIn "The queue ticket, now left."
+// @Failure 400 {object} ErrorResponse "Invalid queue ID format."
+// @Failure 403 {object} ErrorResponse "Not allowed to change this ticket."
+// @Failure 404 {object} ErrorResponse "Queue ticket not found with the specified ID."
+// @Failure 409 {object} ErrorResponse "The ticket is no longer in the queue."
+// @Router /queue/{id} [delete]
+func LeaveQueue(c *gin.Context) {
+	transitionWalkIn(c, models.WalkInLeft, 0)
+}
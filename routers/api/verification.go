@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/punchanabu/redrice-backend-go/models"
+)
+
+type VerifyEmailDetails struct {
+	Token string `json:"token" example:""`
+}
+
+func sendVerificationEmail(user *models.User) error {
+	token, err := userTokenHandler.CreateToken(user.ID, models.TokenPurposeEmailVerification, models.EmailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := os.Getenv("APP_URL") + "/verify-email?token=" + token
+	body := fmt.Sprintf(
+		"Hi %s,\n\nWelcome to RedRice! Please confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours.",
+		user.Name, link, int(models.EmailVerificationTokenTTL.Hours()),
+	)
+
+	return mailer.Send(user.Email, "Verify your RedRice email", body)
+}
+
+// @Summary Verify Email
+// @Description Confirms the email address of an account using the token from the verification link.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param body body VerifyEmailDetails true "Verification Token"
+// @Success 200 {object} MessageResponse "The email has been verified."
+// @Failure 400 {object} ErrorResponse "The token is invalid, expired or already used."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/verify-email [post]
+func VerifyEmail(c *gin.Context) {
+	var details VerifyEmailDetails
+	if err := c.ShouldBindJSON(&details); err != nil || details.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input format! please check the input format"})
+		return
+	}
+
+	userToken, err := userTokenHandler.ConsumeToken(details.Token, models.TokenPurposeEmailVerification)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification token is invalid or expired"})
+		return
+	}
+
+	if err := userHandler.MarkEmailVerified(userToken.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error verifying email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// @Summary Resend Verification Email
+// @Description Sends a new verification link to the email of the current user. Older links stop working.
+// @Tags authentication
+// @Produce json
+// @security BearerAuth
+// @Success 200 {object} MessageResponse "The verification link has been sent."
+// @Failure 400 {object} ErrorResponse "The email is already verified."
+// @Failure 401 {object} ErrorResponse "Unauthorized."
+// @Failure 500 {object} ErrorResponse "Internal server error, unable to process the request."
+// @Router /auth/resend-verification [post]
+func ResendVerification(c *gin.Context) {
+	id, _ := c.Get("id")
+
+	user, err := userHandler.GetUser(id.(uint))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.IsEmailVerified() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error sending verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
@@ -25,11 +25,26 @@ func UseRouter() *gin.Engine {
 	docs.SwaggerInfo.BasePath = "/api/v1"
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	r.GET("/.well-known/jwks.json", api.GetJWKS)
 
 	apiv1 := r.Group("/api/v1")
 	auth := apiv1.Group("/auth")
 	auth.POST("/signin", api.Login)
 	auth.POST("/register", api.Register)
+	auth.POST("/refresh", api.Refresh)
+	auth.POST("/forgot-password", api.ForgotPassword)
+	auth.POST("/reset-password", api.ResetPassword)
+	auth.POST("/verify-email", api.VerifyEmail)
+	auth.POST("/resend-verification", middleware.Auth(), api.ResendVerification)
+	auth.POST("/mfa/verify", api.VerifyMFA)
+	auth.POST("/mfa/enroll", middleware.Auth(), api.EnrollMFA)
+	auth.POST("/mfa/confirm", middleware.Auth(), api.ConfirmMFA)
+	auth.POST("/mfa/disable", middleware.Auth(), api.DisableMFA)
+	auth.POST("/logout", middleware.Auth(), api.Logout)
+	auth.POST("/logout-all", middleware.Auth(), api.LogoutAll)
+	// calendar apps can't log in, the feeds check their own secret token
+	apiv1.GET("/users/:id/reservations.ics", v1.GetUserCalendarFeed)
+	apiv1.GET("/restaurants/:id/reservations.ics", v1.GetRestaurantCalendarFeed)
 	apiv1.Use(middleware.Auth())
 	{
 		// for authorized user
@@ -39,28 +54,72 @@ func UseRouter() *gin.Engine {
 		apiv1.GET("/reservations/:id", v1.GetReservation)
 		apiv1.GET("/users", v1.GetUsers)
 		apiv1.GET("/me", v1.GetMe)
+		apiv1.PUT("/me/password", v1.ChangePassword)
+		apiv1.POST("/me/calendar-feed", v1.CreateMyCalendarFeed)
 		apiv1.GET("/users/:id", v1.GetUser)
 		apiv1.GET("/users/:id/reservations", v1.GetUserReservations)
 		apiv1.GET("/restaurants/:id/comments", v1.GetRestaurantComments)
+		apiv1.GET("/restaurants/:id/tables", v1.GetRestaurantTables)
+		apiv1.GET("/restaurants/:id/availability", v1.GetRestaurantAvailability)
+		apiv1.GET("/availability", v1.SearchAvailability)
+		apiv1.GET("/waitlist", v1.GetMyWaitlist)
+		apiv1.GET("/booking-policy", v1.GetGlobalBookingPolicy)
+		apiv1.GET("/restaurants/:id/booking-policy", v1.GetRestaurantBookingPolicy)
+		apiv1.POST("/restaurants/:id/waitlist", middleware.Verified(), v1.JoinWaitlist)
+		apiv1.POST("/waitlist/:id/confirm", v1.ConfirmWaitlistOffer)
+		apiv1.DELETE("/waitlist/:id", v1.LeaveWaitlist)
+		apiv1.POST("/restaurants/:id/queue", middleware.Verified(), v1.JoinQueue)
+		apiv1.GET("/queue", v1.GetMyQueue)
+		apiv1.GET("/queue/:id", v1.GetQueueTicket)
+		apiv1.POST("/queue/:id/call", v1.CallWalkIn)
+		apiv1.POST("/queue/:id/seat", v1.SeatWalkIn)
+		apiv1.POST("/queue/:id/skip", v1.SkipWalkIn)
+		apiv1.POST("/queue/:id/finish", v1.FinishWalkIn)
+		apiv1.DELETE("/queue/:id", v1.LeaveQueue)
 		apiv1.GET("/comments", v1.GetComments)
 		apiv1.GET("/comments/:id", v1.GetComment)
-		apiv1.POST("/reservations", v1.CreateReservation)
-		apiv1.POST("/comments", v1.CreateComment)
+		apiv1.POST("/reservations", middleware.Verified(), v1.CreateReservation)
+		apiv1.POST("/reservation-series", middleware.Verified(), v1.CreateReservationSeries)
+		apiv1.GET("/reservation-series/:id", v1.GetReservationSeries)
+		apiv1.POST("/comments", middleware.Verified(), v1.CreateComment)
 		apiv1.PUT("/reservations/:id", v1.UpdateReservation)
+		apiv1.GET("/reservations/:id/status-changes", v1.GetReservationStatusChanges)
+		apiv1.GET("/reservations/:id/history", v1.GetReservationHistory)
+		apiv1.GET("/reservations/:id/qr", v1.GetReservationQR)
+		apiv1.POST("/reservations/:id/confirm", v1.ConfirmReservation)
+		apiv1.POST("/reservations/:id/seat", v1.SeatReservation)
+		apiv1.POST("/reservations/:id/complete", v1.CompleteReservation)
+		apiv1.POST("/reservations/:id/cancel", v1.CancelReservation)
+		apiv1.POST("/reservations/:id/no-show", v1.NoShowReservation)
+		apiv1.PUT("/users/:id", v1.UpdateUser)
 		apiv1.PUT("/comments/:id", v1.UpdateComment)
 		apiv1.DELETE("/reservations/:id", v1.DeleteReservation)
 		apiv1.DELETE("/comments/:id", v1.DeleteComment)
-		// for admin
-		adminRoutes := apiv1.Group("/")
-		adminRoutes.Use(middleware.Admin())
-		{
-			adminRoutes.POST("/users", v1.CreateUser)
-			adminRoutes.PUT("/users/:id", v1.UpdateUser)
-			adminRoutes.DELETE("/users/:id", v1.DeleteUser)
-			adminRoutes.POST("/restaurants", v1.CreateRestaurant)
-			adminRoutes.PUT("/restaurants/:id", v1.UpdateRestaurant)
-			adminRoutes.DELETE("/restaurants/:id", v1.DeleteRestaurant)
-		}
+		// for users with the right role
+		apiv1.POST("/users", middleware.Require(middleware.PermUsersManage), v1.CreateUser)
+		apiv1.DELETE("/users/:id", middleware.Require(middleware.PermUsersManage), v1.DeleteUser)
+		apiv1.POST("/restaurants", middleware.Require(middleware.PermRestaurantsCreate), v1.CreateRestaurant)
+		apiv1.PUT("/restaurants/:id", middleware.Require(middleware.PermRestaurantsUpdate), v1.UpdateRestaurant)
+		apiv1.DELETE("/restaurants/:id", middleware.Require(middleware.PermRestaurantsDelete), v1.DeleteRestaurant)
+		apiv1.GET("/restaurants/:id/reservations", middleware.Require(middleware.PermRestaurantReservationsRead), v1.GetRestaurantReservations)
+		apiv1.GET("/restaurants/:id/reservations/day", middleware.Require(middleware.PermRestaurantReservationsRead), v1.GetRestaurantDayView)
+		apiv1.POST("/restaurants/:id/reservations/:reservationId/confirm", middleware.Require(middleware.PermRestaurantReservationsManage), v1.ConfirmRestaurantReservation)
+		apiv1.POST("/restaurants/:id/reservations/:reservationId/seat", middleware.Require(middleware.PermRestaurantReservationsManage), v1.SeatRestaurantReservation)
+		apiv1.POST("/restaurants/:id/reservations/:reservationId/cancel", middleware.Require(middleware.PermRestaurantReservationsManage), v1.CancelRestaurantReservation)
+		apiv1.POST("/restaurants/:id/check-in", middleware.Require(middleware.PermRestaurantReservationsManage), v1.CheckInReservation)
+		apiv1.POST("/restaurants/:id/calendar-feed", middleware.Require(middleware.PermRestaurantsUpdate), v1.CreateRestaurantCalendarFeed)
+		apiv1.PUT("/restaurants/:id/schedule", middleware.Require(middleware.PermRestaurantsUpdate), v1.UpdateRestaurantSchedule)
+		apiv1.POST("/restaurants/:id/exceptions", middleware.Require(middleware.PermRestaurantsUpdate), v1.CreateOpeningException)
+		apiv1.DELETE("/restaurants/:id/exceptions/:exceptionId", middleware.Require(middleware.PermRestaurantsUpdate), v1.DeleteOpeningException)
+		apiv1.POST("/restaurants/:id/tables", middleware.Require(middleware.PermRestaurantTablesManage), v1.CreateTable)
+		apiv1.PUT("/restaurants/:id/tables/:tableId", middleware.Require(middleware.PermRestaurantTablesManage), v1.UpdateTable)
+		apiv1.DELETE("/restaurants/:id/tables/:tableId", middleware.Require(middleware.PermRestaurantTablesManage), v1.DeleteTable)
+		apiv1.GET("/restaurants/:id/waitlist", middleware.Require(middleware.PermRestaurantReservationsRead), v1.GetRestaurantWaitlist)
+		apiv1.GET("/restaurants/:id/queue", middleware.Require(middleware.PermRestaurantReservationsRead), v1.GetRestaurantQueue)
+		apiv1.PUT("/booking-policy", middleware.Require(middleware.PermBookingPolicyManage), v1.UpdateGlobalBookingPolicy)
+		apiv1.PUT("/restaurants/:id/booking-policy", middleware.Require(middleware.PermRestaurantsUpdate), v1.UpdateRestaurantBookingPolicy)
+		apiv1.GET("/lockouts", middleware.Require(middleware.PermUsersManage), api.GetLockouts)
+		apiv1.DELETE("/lockouts", middleware.Require(middleware.PermUsersManage), api.ClearLockout)
 	}
 	return r
 }
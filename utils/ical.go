@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// iCalendar (RFC 5545) output for calendar apps to subscribe to 📅
+const icalTimeFormat = "20060102T150405Z"
+
+type ICalEvent struct {
+	UID          string
+	Sequence     int
+	Start        time.Time
+	End          time.Time
+	LastModified time.Time
+	Summary      string
+	Location     string
+	Description  string
+	// TENTATIVE, CONFIRMED or CANCELLED
+	Status string
+}
+
+// Build a whole VCALENDAR with the events in it, lines end in CRLF like the RFC wants
+func ICalendar(name string, events []ICalEvent) string {
+	var b strings.Builder
+	now := time.Now()
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//RedRice//Reservations//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+EscapeICalText(name))
+	for _, event := range events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+event.UID)
+		writeICalLine(&b, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		writeICalLine(&b, "DTSTAMP:"+now.UTC().Format(icalTimeFormat))
+		if !event.LastModified.IsZero() {
+			writeICalLine(&b, "LAST-MODIFIED:"+event.LastModified.UTC().Format(icalTimeFormat))
+		}
+		writeICalLine(&b, "DTSTART:"+event.Start.UTC().Format(icalTimeFormat))
+		writeICalLine(&b, "DTEND:"+event.End.UTC().Format(icalTimeFormat))
+		writeICalLine(&b, "SUMMARY:"+EscapeICalText(event.Summary))
+		if event.Location != "" {
+			writeICalLine(&b, "LOCATION:"+EscapeICalText(event.Location))
+		}
+		if event.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+EscapeICalText(event.Description))
+		}
+		if event.Status != "" {
+			writeICalLine(&b, "STATUS:"+event.Status)
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+	writeICalLine(&b, "END:VCALENDAR")
+
+	return b.String()
+}
+
+// Escape a TEXT value, backslashes first so the others aren't escaped twice
+func EscapeICalText(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, ";", "\\;")
+	s = strings.ReplaceAll(s, ",", "\\,")
+	s = strings.ReplaceAll(s, "\r\n", "\\n")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return s
+}
+
+// Lines longer than 75 octets are folded with CRLF and a space,
+// without splitting a multi-byte character in half
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The space at the start of the next line counts towards its length
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
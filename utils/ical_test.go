@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Dinner at RedRice", "Dinner at RedRice"},
+		{"separators", "Table 4; party of 2, window", `Table 4\; party of 2\, window`},
+		{"backslash first", `a\;b`, `a\\\;b`},
+		{"newlines", "line one\nline two\r\nline three", `line one\nline two\nline three`},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeICalText(tt.in); got != tt.want {
+				t.Errorf("EscapeICalText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteICalLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{"short", "SUMMARY:Dinner", 1},
+		{"exactly 75 octets", "DESCRIPTION:" + strings.Repeat("a", 63), 1},
+		{"76 octets", "DESCRIPTION:" + strings.Repeat("a", 64), 2},
+		{"long ascii", "DESCRIPTION:" + strings.Repeat("a", 300), 5},
+		{"thai", "LOCATION:" + strings.Repeat("ร้านข้าว", 20), 7},
+		{"emoji on the boundary", "SUMMARY:" + strings.Repeat("a", 66) + strings.Repeat("🍚", 10), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeICalLine(&b, tt.line)
+			out := b.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q doesn't end in CRLF", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("got %d lines, want %d", len(lines), tt.lines)
+			}
+
+			var unfolded strings.Builder
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets long", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a character: %q", i, line)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(line, " ") {
+						t.Fatalf("continuation line %d doesn't start with a space: %q", i, line)
+					}
+					line = line[1:]
+				}
+				unfolded.WriteString(line)
+			}
+			if unfolded.String() != tt.line {
+				t.Errorf("unfolding gives %q, want %q", unfolded.String(), tt.line)
+			}
+		})
+	}
+}
+
+func TestICalendar(t *testing.T) {
+	start := time.Date(2024, 5, 1, 18, 0, 0, 0, time.FixedZone("ICT", 7*60*60))
+	out := ICalendar("My, bookings", []ICalEvent{{
+		UID:      "reservation-1@redrice",
+		Sequence: 2,
+		Start:    start,
+		End:      start.Add(2 * time.Hour),
+		Summary:  "Dinner; 2 guests",
+		Status:   "CONFIRMED",
+	}})
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:My\\, bookings\r\n",
+		"UID:reservation-1@redrice\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTART:20240501T110000Z\r\n",
+		"DTEND:20240501T130000Z\r\n",
+		"SUMMARY:Dinner\\; 2 guests\r\n",
+		"STATUS:CONFIRMED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar is missing %q", want)
+		}
+	}
+	if strings.Contains(out, "LOCATION:") {
+		t.Error("empty location should be left out")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,36 +19,44 @@ import (
 )
 
 var minioClient *minio.Client
+var minioOnce sync.Once
+var minioErr error
 
-func init() {
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found")
-	}
+// The client is made on the first upload, so the package can be used without bucket settings
+func getMinioClient() (*minio.Client, error) {
+	minioOnce.Do(func() {
+		if err := godotenv.Load(); err != nil {
+			fmt.Println("No .env file found")
+		}
 
-	endpoint := os.Getenv("BUCKET_ENDPOINT")
-	accessKeyID := os.Getenv("BUCKET_ACCESS_KEY")
-	secretAccessKey := os.Getenv("BUCKET_SECRET_ACCESS_KEY")
-	useSSL := true
+		endpoint := os.Getenv("BUCKET_ENDPOINT")
+		accessKeyID := os.Getenv("BUCKET_ACCESS_KEY")
+		secretAccessKey := os.Getenv("BUCKET_SECRET_ACCESS_KEY")
+		useSSL := true
 
-	var err error
-	minioClient, err = minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
-		Secure: useSSL,
+		minioClient, minioErr = minio.New(endpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+			Secure: useSSL,
+		})
+		if minioErr != nil {
+			log.Printf("Failed to initialize MinIO client: %s\n", minioErr)
+		}
 	})
-	if err != nil {
-		fmt.Printf("Failed to initialize MinIO client: %s\n", err)
-		os.Exit(1)
-	}
+	return minioClient, minioErr
 }
 
 func UploadImageToS3(bucketName string, file io.Reader, fileName string) (string, error) {
+	client, err := getMinioClient()
+	if err != nil {
+		return "", err
+	}
 
 	key := filepath.Join("images", uuid.New().String()+filepath.Ext(fileName))
 	contentType := mime.TypeByExtension(filepath.Ext(fileName))
 	if contentType == "" {
 		contentType = "image/jpeg"
 	}
-	_, err := minioClient.PutObject(context.Background(), bucketName, key, file, -1, minio.PutObjectOptions{ContentType: contentType})
+	_, err = client.PutObject(context.Background(), bucketName, key, file, -1, minio.PutObjectOptions{ContentType: contentType})
 	if err != nil {
 		log.Printf("Failed to upload to S3: %v", err)
 		return "", err
@@ -56,7 +65,7 @@ func UploadImageToS3(bucketName string, file io.Reader, fileName string) (string
 	reqParams := make(url.Values)
 	reqParams.Set("response-content-disposition", "inline")
 
-	presignedURL, err := minioClient.PresignedGetObject(context.Background(), bucketName, key,7*24*time.Hour, reqParams)
+	presignedURL, err := client.PresignedGetObject(context.Background(), bucketName, key,7*24*time.Hour, reqParams)
 
 	if err != nil {
 		log.Printf("Failed to generate presigned URL: %v", err)
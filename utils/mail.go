@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Anything that can deliver an email, swap it out to capture mails in tests
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to string, subject string, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{to}, []byte(msg))
+}
+
+// Prints mails to the log instead of sending them, handy for local development.
+// The mails carry password reset and verification links, never use it in production.
+type LogMailer struct{}
+
+func (m *LogMailer) Send(to string, subject string, body string) error {
+	log.Printf("Mail to %s: %s\n%s\n", to, subject, body)
+	return nil
+}
+
+// Use SMTP when SMTP_HOST is configured. Mails are only written to the log instead when
+// MAIL_TO_LOG is true, so a deploy that forgot SMTP_HOST doesn't log reset links.
+func NewMailerFromEnv() (Mailer, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		if os.Getenv("MAIL_TO_LOG") != "true" {
+			return nil, fmt.Errorf("SMTP_HOST is not set, set MAIL_TO_LOG=true to write mails to the log during local development")
+		}
+		fmt.Println("No SMTP_HOST configured, mails will be written to the log")
+		return &LogMailer{}, nil
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "25"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@redrice.app"
+	}
+
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA1 test vectors of RFC 6238 appendix B, cut to the last 6 digits
+var rfc6238Key = []byte("12345678901234567890")
+
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			if got := totpCode(rfc6238Key, tt.unix/totpPeriod); got != tt.want {
+				t.Errorf("totpCode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString(rfc6238Key)
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		ok       bool
+	}{
+		{"current step", secret, "050471", step, true},
+		{"with spaces", secret, " 050 471 ", step, true},
+		{"lower case secret", strings.ToLower(secret), "050471", step, true},
+		{"one step behind", secret, totpCode(rfc6238Key, step-1), step - 1, true},
+		{"one step ahead", secret, totpCode(rfc6238Key, step+1), step + 1, true},
+		{"two steps behind", secret, totpCode(rfc6238Key, step-2), 0, false},
+		{"two steps ahead", secret, totpCode(rfc6238Key, step+2), 0, false},
+		{"wrong code", secret, "123456", 0, false},
+		{"too short", secret, "05047", 0, false},
+		{"eight digits", secret, "14050471", 0, false},
+		{"invalid secret", "not base32!", "050471", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ValidateTOTP(tt.secret, tt.code, now)
+			if ok != tt.ok || got != tt.wantStep {
+				t.Errorf("ValidateTOTP() = %d, %v, want %d, %v", got, ok, tt.wantStep, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q isn't base32: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("key is %d bytes, want 20", len(key))
+	}
+
+	// A code made from the secret is accepted
+	now := time.Now()
+	if _, ok := ValidateTOTP(secret, totpCode(key, now.Unix()/totpPeriod), now); !ok {
+		t.Error("code for the generated secret isn't accepted")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(TOTPProvisioningURI("JBSWY3DPEHPK3PXP", "somchai@example.com", "RedRice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/RedRice:somchai@example.com" {
+		t.Errorf("unexpected uri %s", uri)
+	}
+	query := uri.Query()
+	for key, want := range map[string]string{"secret": "JBSWY3DPEHPK3PXP", "issuer": "RedRice", "algorithm": "SHA1", "digits": "6", "period": "30"} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}